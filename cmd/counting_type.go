@@ -2,6 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/onozaty/filep/extract/extractor"
 
 	"github.com/spf13/pflag"
 )
@@ -12,71 +15,85 @@ const (
 	Bytes CountingType = iota
 	Chars
 	Lines
+	Width
 )
 
+type countingTypeFlag struct {
+	name         string
+	shorthand    string
+	countingType CountingType
+}
+
+var countingTypeFlags = []countingTypeFlag{
+	{name: "byte", shorthand: "b", countingType: Bytes},
+	{name: "char", shorthand: "c", countingType: Chars},
+	{name: "line", shorthand: "l", countingType: Lines},
+	{name: "width", shorthand: "w", countingType: Width},
+}
+
 func getFlagCountingType(f *pflag.FlagSet) (CountingType, error) {
 
-	handleByte, _ := f.GetBool("byte")
-	handleChar, _ := f.GetBool("char")
-	handleLine, _ := f.GetBool("line")
+	var countingType CountingType
 
 	selected := 0
-	if handleByte {
-		selected++
-	}
-	if handleChar {
-		selected++
-	}
-	if handleLine {
-		selected++
+	for _, flag := range definedCountingTypeFlags(f) {
+		if handle, _ := f.GetBool(flag.name); handle {
+			selected++
+			countingType = flag.countingType
+		}
 	}
 
 	if selected != 1 {
-		return 0, fmt.Errorf("specify one of the following: -b, -c, -l")
+		return 0, newCountingTypeError(f)
 	}
 
-	if handleByte {
-		return Bytes, nil
-	}
-	if handleChar {
-		return Chars, nil
-	}
-	return Lines, nil
+	return countingType, nil
 }
 
 func getFlagCountingTypeWithNumber(f *pflag.FlagSet) (CountingType, int64, error) {
 
-	byteNum := getFlagNum(f, "byte")
-	charNum := getFlagNum(f, "char")
-	lineNum := getFlagNum(f, "line")
-
 	var selectNum int64
 	var countingType CountingType
 
 	selected := 0
-	if byteNum != nil {
-		selected++
-		selectNum = *byteNum
-		countingType = Bytes
-	}
-	if charNum != nil {
-		selected++
-		selectNum = *charNum
-		countingType = Chars
-	}
-	if lineNum != nil {
-		selected++
-		selectNum = *lineNum
-		countingType = Lines
+	for _, flag := range definedCountingTypeFlags(f) {
+		if num := getFlagNum(f, flag.name); num != nil {
+			selected++
+			selectNum = *num
+			countingType = flag.countingType
+		}
 	}
 
 	if selected != 1 {
-		return 0, 0, fmt.Errorf("specify one of the following: -b, -c, -l")
+		return 0, 0, newCountingTypeError(f)
 	}
 
 	return countingType, selectNum, nil
 }
 
+// コマンド毎に指定できる単位が異なるため、定義されているフラグのみを対象とします。
+func definedCountingTypeFlags(f *pflag.FlagSet) []countingTypeFlag {
+
+	flags := []countingTypeFlag{}
+	for _, flag := range countingTypeFlags {
+		if f.Lookup(flag.name) != nil {
+			flags = append(flags, flag)
+		}
+	}
+
+	return flags
+}
+
+func newCountingTypeError(f *pflag.FlagSet) error {
+
+	shorthands := []string{}
+	for _, flag := range definedCountingTypeFlags(f) {
+		shorthands = append(shorthands, "-"+flag.shorthand)
+	}
+
+	return fmt.Errorf("specify one of the following: %s", strings.Join(shorthands, ", "))
+}
+
 func getFlagNum(f *pflag.FlagSet, name string) *int64 {
 
 	if f.Changed(name) {
@@ -86,3 +103,17 @@ func getFlagNum(f *pflag.FlagSet, name string) *int64 {
 
 	return nil
 }
+
+func getFlagCharUnit(f *pflag.FlagSet) (extractor.CharUnit, error) {
+
+	unit, _ := f.GetString("char-unit")
+
+	switch unit {
+	case "rune":
+		return extractor.CharUnitRune, nil
+	case "grapheme":
+		return extractor.CharUnitGrapheme, nil
+	default:
+		return 0, fmt.Errorf("char-unit must be rune or grapheme")
+	}
+}
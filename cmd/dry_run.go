@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// 出力先には書き込まずに、処理した場合の結果をメモリ上で求めます。
+func dryRun(input io.Reader, inputPath string, outputPath string, process processFunc) (fileResult, error) {
+
+	inputBytes, err := io.ReadAll(input)
+	if err != nil {
+		return fileResult{}, err
+	}
+
+	output := new(bytes.Buffer)
+	processResult, err := process(bytes.NewReader(inputBytes), output)
+	if err != nil {
+		return fileResult{}, err
+	}
+
+	return fileResult{
+		processResult:  processResult,
+		inputPath:      inputPath,
+		outputPath:     outputPath,
+		inputBytes:     int64(len(inputBytes)),
+		outputBytes:    int64(output.Len()),
+		changed:        !bytes.Equal(inputBytes, output.Bytes()),
+		inputContents:  inputBytes,
+		outputContents: output.Bytes(),
+	}, nil
+}
+
+func printDryRunResult(w io.Writer, result fileResult) error {
+
+	status := "unchanged"
+	if result.changed {
+		status = "changed"
+	}
+
+	details := fmt.Sprintf("%d -> %d bytes", result.inputBytes, result.outputBytes)
+	if result.replacements != nil {
+		details = fmt.Sprintf("%d matches, %s", *result.replacements, details)
+	}
+
+	_, err := fmt.Fprintf(w, "%s: %s (%s)\n", status, result.inputPath, details)
+	return err
+}
@@ -2,10 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"math"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/onozaty/filep/extract/extractor"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -24,18 +29,117 @@ func newExtractCmd() *cobra.Command {
 			// endの指定が無かった場合には、ファイル終端までを対象にするためにint64の最大値を入れておく
 			end := getFlagInt64(cmd.Flags(), "end", math.MaxInt64)
 
-			countingType, err := getFlagCountingType(cmd.Flags())
+			ranges, err := getFlagRanges(cmd.Flags(), "ranges")
 			if err != nil {
 				return err
 			}
+			if ranges != nil && (cmd.Flags().Changed("start") || cmd.Flags().Changed("end")) {
+				return fmt.Errorf("--ranges cannot be specified with --start or --end")
+			}
+
+			fromRegex, err := getFlagRegexp(cmd.Flags(), "from-regex")
+			if err != nil {
+				return err
+			}
+			toRegex, err := getFlagRegexp(cmd.Flags(), "to-regex")
+			if err != nil {
+				return err
+			}
+			excludeDelimiters, _ := cmd.Flags().GetBool("exclude-delimiters")
+			firstBlock, _ := cmd.Flags().GetBool("first-block")
+
+			fields, err := getFlagRanges(cmd.Flags(), "fields")
+			if err != nil {
+				return err
+			}
+			delimiter, err := getFlagEscapedString(cmd.Flags(), "delimiter", true)
+			if err != nil {
+				return err
+			}
+			csv, _ := cmd.Flags().GetBool("csv")
+
+			handleByte, _ := cmd.Flags().GetBool("byte")
+			handleChar, _ := cmd.Flags().GetBool("char")
+			handleWidth, _ := cmd.Flags().GetBool("width")
+
+			var countingType CountingType
+			switch {
+			case fields != nil:
+				// フィールドは行ごとに取り出す
+				if ranges != nil || cmd.Flags().Changed("start") || cmd.Flags().Changed("end") || fromRegex != nil || toRegex != nil {
+					return fmt.Errorf("--fields cannot be specified with --start, --end, --ranges, --from-regex or --to-regex")
+				}
+				if handleByte || handleChar || handleWidth {
+					return fmt.Errorf("--fields can only be used with -l")
+				}
+				countingType = Lines
+			case fromRegex != nil || toRegex != nil:
+				// 正規表現で範囲を指定する場合は行単位
+				if ranges != nil || cmd.Flags().Changed("start") || cmd.Flags().Changed("end") {
+					return fmt.Errorf("--from-regex and --to-regex cannot be specified with --start, --end or --ranges")
+				}
+				if handleByte || handleChar || handleWidth {
+					return fmt.Errorf("--from-regex and --to-regex can only be used with -l")
+				}
+				countingType = Lines
+			default:
+				countingType, err = getFlagCountingType(cmd.Flags())
+				if err != nil {
+					return err
+				}
+			}
+
+			if (excludeDelimiters || firstBlock) && fromRegex == nil && toRegex == nil {
+				return fmt.Errorf("--exclude-delimiters and --first-block can only be specified with --from-regex or --to-regex")
+			}
+			if (cmd.Flags().Changed("delimiter") || csv) && fields == nil {
+				return fmt.Errorf("--delimiter and --csv can only be specified with --fields")
+			}
+			if delimiter == "" {
+				return fmt.Errorf("delimiter must not be empty")
+			}
+
+			charBoundary, _ := cmd.Flags().GetBool("char-boundary")
+			if charBoundary && countingType != Bytes {
+				return fmt.Errorf("--char-boundary can only be specified with -b")
+			}
+
+			newline, err := getFlagNewline(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("newline") && countingType != Lines {
+				return fmt.Errorf("--newline can only be specified with -l")
+			}
+
+			charUnit, err := getFlagCharUnit(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("char-unit") && countingType != Chars {
+				return fmt.Errorf("--char-unit can only be specified with -c")
+			}
+
+			ambiguousWidth, _ := cmd.Flags().GetInt("ambiguous-width")
+			if cmd.Flags().Changed("ambiguous-width") && countingType != Width {
+				return fmt.Errorf("--ambiguous-width can only be specified with -w")
+			}
 
-			recursive, _ := cmd.Flags().GetBool("recursive")
 			encoding, _ := cmd.Flags().GetString("encoding")
 
-			if start <= 0 {
-				return fmt.Errorf("start must be greater than or equal to 1")
+			handleOptions, err := getHandleOptions(cmd)
+			if err != nil {
+				return err
+			}
+
+			// 負の値は末尾からの位置
+			if start == 0 {
+				return fmt.Errorf("start must not be 0")
+			}
+			if end == 0 {
+				return fmt.Errorf("end must not be 0")
 			}
-			if start > end {
+			if (start > 0) == (end > 0) && start > end {
 				return fmt.Errorf("end must be greater than or equal to start")
 			}
 
@@ -46,67 +150,232 @@ func newExtractCmd() *cobra.Command {
 				inputPath,
 				outputPath,
 				extractCondition{
-					start:        start,
-					end:          end,
-					countingType: countingType,
+					start:          start,
+					end:            end,
+					ranges:         ranges,
+					countingType:   countingType,
+					charBoundary:   charBoundary,
+					newline:        newline,
+					charUnit:       charUnit,
+					ambiguousWidth: ambiguousWidth,
+					lineBlock: lineBlockCondition{
+						fromRegex:         fromRegex,
+						toRegex:           toRegex,
+						excludeDelimiters: excludeDelimiters,
+						firstOnly:         firstBlock,
+					},
+					field: fieldCondition{
+						fields:    fields,
+						delimiter: delimiter,
+						csv:       csv,
+					},
 				},
 				encoding,
-				recursive)
+				handleOptions)
 		},
 	}
 
 	extractCmd.Flags().StringP("input", "i", "", "Input file/dir path.")
 	extractCmd.MarkFlagRequired("input")
 	extractCmd.Flags().StringP("output", "o", "", "Output file/dir path.")
-	extractCmd.MarkFlagRequired("output")
 
-	extractCmd.Flags().Int64P("start", "s", 0, "Start position.")
-	extractCmd.Flags().Int64P("end", "e", 0, "End position.")
+	extractCmd.Flags().Int64P("start", "s", 0, "Start position. (negative counts from the end)")
+	extractCmd.Flags().Int64P("end", "e", 0, "End position. (negative counts from the end)")
+	extractCmd.Flags().StringP("ranges", "", "", "Comma separated ranges to extract. (e.g. 1-3,100-200,500-)")
+	extractCmd.Flags().StringP("from-regex", "", "", "Regex of the line where the block to extract begins.")
+	extractCmd.Flags().StringP("to-regex", "", "", "Regex of the line where the block to extract ends.")
+	extractCmd.Flags().BoolP("exclude-delimiters", "", false, "Exclude the lines matched by --from-regex and --to-regex.")
+	extractCmd.Flags().BoolP("first-block", "", false, "Extract only the first block.")
+	extractCmd.Flags().StringP("fields", "f", "", "Comma separated fields to extract from each line. (e.g. 2,5-7)")
+	extractCmd.Flags().StringP("delimiter", "", "\t", "Delimiter of fields. (escape sequences can be used)")
+	extractCmd.Flags().BoolP("csv", "", false, "Do not split fields at delimiters and line breaks in double quotes.")
 
 	extractCmd.Flags().BoolP("byte", "b", false, "Handle by bytes.")
 	extractCmd.Flags().BoolP("char", "c", false, "Handle by characters.")
 	extractCmd.Flags().BoolP("line", "l", false, "Handle by lines.")
+	extractCmd.Flags().BoolP("width", "w", false, "Handle by display width.")
+	extractCmd.Flags().BoolP("char-boundary", "", false, "Do not cut in the middle of a character with -b.")
+	extractCmd.Flags().StringP("char-unit", "", "rune", "Unit of characters for -c. (rune or grapheme)")
+	extractCmd.Flags().IntP("ambiguous-width", "", 1, "Width of East Asian ambiguous characters for -w. (1 or 2)")
+	addNewlineFlag(extractCmd.Flags(), "-l")
 
-	extractCmd.Flags().BoolP("recursive", "", false, "Recursively traverse the input dir.")
+	addHandleFlags(extractCmd.Flags())
 	extractCmd.Flags().StringP("encoding", "", "UTF-8", "Encoding.")
 
 	return extractCmd
 }
 
 type extractCondition struct {
-	start        int64
-	end          int64
+	start int64
+	end   int64
+	// 複数の範囲(指定された場合はstartとendより優先)
+	ranges       []extractor.Range
 	countingType CountingType
+	// バイト単位の場合に文字の途中で区切らないか
+	charBoundary bool
+	// 行単位の場合の改行
+	newline extractor.Newline
+	// 文字単位の場合に文字として数える単位
+	charUnit extractor.CharUnit
+	// 表示幅の場合にEast Asian Widthが曖昧な文字の幅
+	ambiguousWidth int
+	// 正規表現にマッチする行で囲まれたブロック(指定された場合は位置より優先)
+	lineBlock lineBlockCondition
+	// 行ごとのフィールド(指定された場合は位置より優先)
+	field fieldCondition
 }
 
-func runExtract(inputPath string, outputPath string, condition extractCondition, encoding string, recursive bool) error {
+type lineBlockCondition struct {
+	fromRegex         *regexp.Regexp
+	toRegex           *regexp.Regexp
+	excludeDelimiters bool
+	firstOnly         bool
+}
+
+type fieldCondition struct {
+	fields    []extractor.Range
+	delimiter string
+	csv       bool
+}
+
+func runExtract(inputPath string, outputPath string, condition extractCondition, encoding string, options handleOptions) error {
 
 	extractor, err := newExtractor(condition, encoding)
 	if err != nil {
 		return err
 	}
 
-	process := func(inputFilePath string, outputFilePath string) error {
-		return extractor.Extract(inputFilePath, outputFilePath)
+	process := func(input io.Reader, output io.Writer) (processResult, error) {
+		return processResult{}, extractor.Extract(input, output)
 	}
 
-	return handle(inputPath, outputPath, process, recursive)
+	return handle(inputPath, outputPath, process, options)
 }
 
 func newExtractor(condition extractCondition, encoding string) (extractor.Extractor, error) {
 
+	if field := condition.field; field.fields != nil {
+		return extractor.NewFieldExtractor(field.fields, field.delimiter, field.csv, condition.newline, encoding)
+	}
+
+	if block := condition.lineBlock; block.fromRegex != nil || block.toRegex != nil {
+		return extractor.NewLineBlockExtractor(block.fromRegex, block.toRegex, block.excludeDelimiters, block.firstOnly, condition.newline, encoding)
+	}
+
+	if condition.ranges != nil {
+		return newRangesExtractor(condition, encoding)
+	}
+
 	switch condition.countingType {
 	case Bytes:
+		if condition.charBoundary {
+			return extractor.NewByteCharBoundaryExtractor(condition.start, condition.end, encoding)
+		}
 		return extractor.NewByteExtractor(condition.start, condition.end)
 	case Chars:
-		return extractor.NewCharExtractor(condition.start, condition.end, encoding)
+		return extractor.NewCharExtractor(condition.start, condition.end, condition.charUnit, encoding)
 	case Lines:
-		return extractor.NewLineExtractor(condition.start, condition.end, encoding)
+		return extractor.NewLineExtractor(condition.start, condition.end, condition.newline, encoding)
+	case Width:
+		return extractor.NewWidthExtractor(condition.start, condition.end, condition.ambiguousWidth, encoding)
 	default:
 		return nil, fmt.Errorf("invalid counting type: %d", condition.countingType)
 	}
 }
 
+func newRangesExtractor(condition extractCondition, encoding string) (extractor.Extractor, error) {
+
+	switch condition.countingType {
+	case Bytes:
+		if condition.charBoundary {
+			return extractor.NewByteCharBoundaryRangesExtractor(condition.ranges, encoding)
+		}
+		return extractor.NewByteRangesExtractor(condition.ranges)
+	case Chars:
+		return extractor.NewCharRangesExtractor(condition.ranges, condition.charUnit, encoding)
+	case Lines:
+		return extractor.NewLineRangesExtractor(condition.ranges, condition.newline, encoding)
+	case Width:
+		return extractor.NewWidthRangesExtractor(condition.ranges, condition.ambiguousWidth, encoding)
+	default:
+		return nil, fmt.Errorf("invalid counting type: %d", condition.countingType)
+	}
+}
+
+// 1-3,100-200,500- のような形式で指定された範囲を解析します。
+func getFlagRanges(f *pflag.FlagSet, name string) ([]extractor.Range, error) {
+
+	if !f.Changed(name) {
+		return nil, nil
+	}
+
+	str, _ := f.GetString(name)
+
+	ranges := []extractor.Range{}
+	for _, item := range strings.Split(str, ",") {
+		r, err := parseRange(strings.TrimSpace(item))
+		if err != nil {
+			return nil, errors.WithMessagef(err, "range %s specified in --%s is invalid", item, name)
+		}
+
+		if r.Start < 1 {
+			return nil, fmt.Errorf("range %s specified in --%s is invalid: start must be greater than or equal to 1", item, name)
+		}
+		if r.Start > r.End {
+			return nil, fmt.Errorf("range %s specified in --%s is invalid: end must be greater than or equal to start", item, name)
+		}
+		if len(ranges) > 0 && r.Start <= ranges[len(ranges)-1].End {
+			return nil, fmt.Errorf("ranges specified in --%s must be in ascending order without overlap", name)
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	return ranges, nil
+}
+
+func parseRange(str string) (extractor.Range, error) {
+
+	startStr, endStr, found := strings.Cut(str, "-")
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil {
+		return extractor.Range{}, err
+	}
+
+	if !found {
+		// 1つだけの場合は、その位置のみ
+		return extractor.Range{Start: start, End: start}, nil
+	}
+
+	if endStr == "" {
+		// 終了位置が省略された場合は末尾まで
+		return extractor.Range{Start: start, End: math.MaxInt64}, nil
+	}
+
+	end, err := strconv.ParseInt(endStr, 10, 64)
+	if err != nil {
+		return extractor.Range{}, err
+	}
+
+	return extractor.Range{Start: start, End: end}, nil
+}
+
+func getFlagRegexp(f *pflag.FlagSet, name string) (*regexp.Regexp, error) {
+
+	str, _ := f.GetString(name)
+	if str == "" {
+		return nil, nil
+	}
+
+	regex, err := regexp.Compile(str)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "regular expression specified in --%s is invalid", name)
+	}
+
+	return regex, nil
+}
+
 func getFlagInt64(f *pflag.FlagSet, name string, defaultValue int64) int64 {
 
 	if f.Changed(name) {
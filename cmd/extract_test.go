@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"bytes"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/onozaty/filep/test"
@@ -630,7 +633,7 @@ func TestExtractCmd_NoNumberSpecified(t *testing.T) {
 	err := rootCmd.Execute()
 
 	// ASSERT
-	require.EqualError(t, err, "specify one of the following: -b, -c, -l")
+	require.EqualError(t, err, "specify one of the following: -b, -c, -l, -w")
 }
 
 func TestExtractCmd_InvalidEncoding(t *testing.T) {
@@ -741,7 +744,7 @@ func TestExtractCmd_InvalidStart(t *testing.T) {
 	err := rootCmd.Execute()
 
 	// ASSERT
-	require.EqualError(t, err, "start must be greater than or equal to 1")
+	require.EqualError(t, err, "start must not be 0")
 }
 
 func TestExtractCmd_InvalidEnd(t *testing.T) {
@@ -768,3 +771,998 @@ func TestExtractCmd_InvalidEnd(t *testing.T) {
 	// ASSERT
 	require.EqualError(t, err, "end must be greater than or equal to start")
 }
+
+func TestExtractCmd_InPlace_File(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteBytes(t, d, "input", []byte{0x01, 0x02, 0x03, 0x04, 0x05})
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-s", "2",
+		"-e", "4",
+		"-b",
+		"--in-place",
+		"--backup-suffix", ".orig",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte{0x02, 0x03, 0x04}, test.ReadBytes(t, input))
+	assert.Equal(t, []byte{0x01, 0x02, 0x03, 0x04, 0x05}, test.ReadBytes(t, input+".orig"))
+}
+
+func TestExtractCmd_SameFile(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "12345")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-s", "2",
+		"-b",
+		"-o", input,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.EqualError(t, err, "input and output are the same file: "+input+" (use --in-place)")
+	assert.Equal(t, "12345", test.ReadString(t, input))
+}
+
+func TestExtractCmd_Dir_Exclude(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateDir(t, d, "input")
+	test.CreateFileWriteString(t, input, "1.txt", "123")
+	test.CreateFileWriteString(t, input, "2.lock", "123")
+
+	inputSub := test.CreateDir(t, input, "sub")
+	test.CreateFileWriteString(t, inputSub, "3.txt", "123")
+	test.CreateFileWriteString(t, inputSub, "4.lock", "123")
+
+	output := test.CreateDir(t, d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-e", "1",
+		"-c",
+		"-o", output,
+		"--recursive",
+		"--exclude", "**/*.lock",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	assert.Equal(t, "1", test.ReadString(t, filepath.Join(output, "1.txt")))
+	assert.NoFileExists(t, filepath.Join(output, "2.lock"))
+	assert.Equal(t, "1", test.ReadString(t, filepath.Join(output, "sub", "3.txt")))
+	assert.NoFileExists(t, filepath.Join(output, "sub", "4.lock"))
+}
+
+func TestExtractCmd_Stdio_Byte(t *testing.T) {
+
+	// ARRANGE
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", "-",
+		"-s", "3",
+		"-e", "5",
+		"-b",
+		"-o", "-",
+	})
+
+	// パイプと同じくシークできない入力
+	rootCmd.SetIn(io.MultiReader(strings.NewReader("12345678")))
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "345", buf.String())
+}
+
+func TestExtractCmd_Stdio_Line(t *testing.T) {
+
+	// ARRANGE
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", "-",
+		"-s", "2",
+		"-l",
+		"-o", "-",
+	})
+
+	rootCmd.SetIn(strings.NewReader("1\n2\n3\n"))
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "2\n3\n", buf.String())
+}
+
+func TestExtractCmd_DryRun(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "1\n2\n3\n")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-s", "2",
+		"-l",
+		"-o", output,
+		"--dry-run",
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "changed: "+input+" (6 -> 4 bytes)\n", buf.String())
+	assert.NoFileExists(t, output)
+}
+
+func TestExtractCmd_Report(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteBytes(t, d, "input.txt", test.StringToByte(t, "あいう\nえお\nかき\n", japanese.ShiftJIS))
+	output := filepath.Join(d, "output.txt")
+	report := filepath.Join(d, "report.json")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-o", output,
+		"-s", "2",
+		"-e", "2",
+		"-l",
+		"--encoding", "sjis",
+		"--report", report,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	// 途中までしか読み込まなくても、入力全体の量が記録されること
+	records := readReport(t, report)
+	assert.Equal(t, []map[string]any{
+		{
+			"input_path":   input,
+			"output_path":  output,
+			"bytes_in":     float64(17),
+			"bytes_out":    float64(5),
+			"chars_in":     float64(10),
+			"chars_out":    float64(3),
+			"lines_in":     float64(3),
+			"lines_out":    float64(1),
+			"replacements": nil,
+			"error":        nil,
+		},
+	}, records)
+}
+
+func TestExtractCmd_FromEnd_Line(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "1\n2\n3\n4\n5\n")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-s", "-3",
+		"-e", "-2",
+		"-l",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "3\n4\n", test.ReadString(t, output))
+}
+
+func TestExtractCmd_FromEnd_Char_Stdin(t *testing.T) {
+
+	// ARRANGE
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", "-",
+		"-s", "-2",
+		"-c",
+		"-o", "-",
+	})
+	rootCmd.SetIn(strings.NewReader("あいうえお"))
+	stdout := new(bytes.Buffer)
+	rootCmd.SetOut(stdout)
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "えお", stdout.String())
+}
+
+func TestExtractCmd_InvalidEnd_Zero(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-s", "-2",
+		"-e", "0",
+		"-l",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.EqualError(t, err, "end must not be 0")
+}
+
+func TestExtractCmd_InvalidEnd_FromEnd(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-s", "-2",
+		"-e", "-3",
+		"-l",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.EqualError(t, err, "end must be greater than or equal to start")
+}
+
+func TestExtractCmd_Ranges(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "1\n2\n3\n4\n5\n6\n7\n8\n")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"--ranges", "1-2,4,7-",
+		"-l",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "1\n2\n4\n7\n8\n", test.ReadString(t, output))
+}
+
+func TestExtractCmd_Ranges_Byte(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "abcdefghij")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"--ranges", "2-3, 5-5, 9-20",
+		"-b",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "bceij", test.ReadString(t, output))
+}
+
+func TestExtractCmd_Ranges_Invalid(t *testing.T) {
+
+	tests := []struct {
+		ranges   string
+		expected string
+	}{
+		{ranges: "1-3,2-5", expected: "ranges specified in --ranges must be in ascending order without overlap"},
+		{ranges: "5-8,1-2", expected: "ranges specified in --ranges must be in ascending order without overlap"},
+		{ranges: "3-2", expected: "range 3-2 specified in --ranges is invalid: end must be greater than or equal to start"},
+		{ranges: "0-2", expected: "range 0-2 specified in --ranges is invalid: start must be greater than or equal to 1"},
+		{ranges: "1-2,", expected: "range  specified in --ranges is invalid: strconv.ParseInt: parsing \"\": invalid syntax"},
+		{ranges: "a-2", expected: "range a-2 specified in --ranges is invalid: strconv.ParseInt: parsing \"a\": invalid syntax"},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs([]string{
+			"extract",
+			"-i", input,
+			"--ranges", tt.ranges,
+			"-l",
+			"-o", output,
+		})
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		assert.EqualError(t, err, tt.expected, tt.ranges)
+	}
+}
+
+func TestExtractCmd_RangesWithStart(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"--ranges", "1-2",
+		"-s", "1",
+		"-l",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.EqualError(t, err, "--ranges cannot be specified with --start or --end")
+}
+
+func TestExtractCmd_FromToRegex(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "a\n[BEGIN]\nb\n[END]\nc\n[BEGIN]\nd\n[END]\n")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"--from-regex", `^\[BEGIN\]`,
+		"--to-regex", `^\[END\]`,
+		"--exclude-delimiters",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "b\nd\n", test.ReadString(t, output))
+}
+
+func TestExtractCmd_FromToRegex_FirstBlock(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "a\n[BEGIN]\nb\n[END]\nc\n[BEGIN]\nd\n[END]\n")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"--from-regex", `^\[BEGIN\]`,
+		"--to-regex", `^\[END\]`,
+		"--first-block",
+		"-l",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "[BEGIN]\nb\n[END]\n", test.ReadString(t, output))
+}
+
+func TestExtractCmd_FromToRegex_Error(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"--from-regex", "[a"},
+			expected: "regular expression specified in --from-regex is invalid: error parsing regexp: missing closing ]: `[a`",
+		},
+		{
+			args:     []string{"--to-regex", "a", "-s", "2"},
+			expected: "--from-regex and --to-regex cannot be specified with --start, --end or --ranges",
+		},
+		{
+			args:     []string{"--from-regex", "a", "-c"},
+			expected: "--from-regex and --to-regex can only be used with -l",
+		},
+		{
+			args:     []string{"--first-block", "-l"},
+			expected: "--exclude-delimiters and --first-block can only be specified with --from-regex or --to-regex",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"extract", "-i", input, "-o", output}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		assert.EqualError(t, err, tt.expected)
+	}
+}
+
+func TestExtractCmd_Fields(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "1\t2\t3\t4\na\tb\tc\td\n")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-f", "1,3-",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "1\t3\t4\na\tc\td\n", test.ReadString(t, output))
+}
+
+func TestExtractCmd_Fields_CSV_SJIS(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteBytes(t, d, "input", test.StringToByte(t, "名前,住所\n\"山田,太郎\",\"東京\"\n", japanese.ShiftJIS))
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-f", "1",
+		"--delimiter", ",",
+		"--csv",
+		"--encoding", "sjis",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "名前\n\"山田,太郎\"\n", test.ByteToString(t, test.ReadBytes(t, output), japanese.ShiftJIS))
+}
+
+func TestExtractCmd_Fields_EscapedDelimiter(t *testing.T) {
+
+	tests := []struct {
+		delimiter string
+		contents  string
+		expected  string
+	}{
+		{delimiter: `\t`, contents: "a\tb\tc\n", expected: "b\n"},
+		{delimiter: `\x1f`, contents: "a\x1fb\x1fc\n", expected: "b\n"},
+		{delimiter: `\\t`, contents: "a\\tb\\tc\n", expected: "b\n"},
+		{delimiter: `"`, contents: "a\"b\"c\n", expected: "b\n"},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", tt.contents)
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs([]string{
+			"extract",
+			"-i", input,
+			"-f", "2",
+			"--delimiter", tt.delimiter,
+			"-o", output,
+		})
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, test.ReadString(t, output), "delimiter=%s", tt.delimiter)
+	}
+}
+
+func TestExtractCmd_Fields_Error(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"-f", "2,1"},
+			expected: "ranges specified in --fields must be in ascending order without overlap",
+		},
+		{
+			args:     []string{"-f", "1", "--ranges", "1-2"},
+			expected: "--fields cannot be specified with --start, --end, --ranges, --from-regex or --to-regex",
+		},
+		{
+			args:     []string{"-f", "1", "-b"},
+			expected: "--fields can only be used with -l",
+		},
+		{
+			args:     []string{"--csv", "-l"},
+			expected: "--delimiter and --csv can only be specified with --fields",
+		},
+		{
+			args:     []string{"-f", "1", "--delimiter", ""},
+			expected: "delimiter must not be empty",
+		},
+		{
+			args:     []string{"-f", "1", "--delimiter", `\q`},
+			expected: "could not parse value \\q of flag delimiter: invalid syntax",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"extract", "-i", input, "-o", output}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		assert.EqualError(t, err, tt.expected)
+	}
+}
+
+func TestExtractCmd_Byte_CharBoundary(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "aあいうb")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-o", output,
+		"-s", "3",
+		"-e", "-3",
+		"-b",
+		"--char-boundary",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "い", test.ReadString(t, output))
+}
+
+func TestExtractCmd_Ranges_Byte_CharBoundary(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "aあいうb")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-o", output,
+		"--ranges", "1-3,5-",
+		"-b",
+		"--char-boundary",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "aいうb", test.ReadString(t, output))
+}
+
+func TestExtractCmd_CharBoundary_NotByte(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-o", output,
+		"-s", "1",
+		"-l",
+		"--char-boundary",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	assert.EqualError(t, err, "--char-boundary can only be specified with -b")
+}
+
+func TestExtractCmd_Line_Newline(t *testing.T) {
+
+	tests := []struct {
+		newline  string
+		expected string
+	}{
+		{newline: "lf", expected: "b\rc\r\n"},
+		{newline: "any", expected: "b\r"},
+		{newline: "unicode", expected: "b\r"},
+		{newline: `\r\n`, expected: "d\u2028e"},
+		{newline: `\u2028`, expected: "e"},
+		{newline: "ANY", expected: "b\r"},
+		{newline: "Unicode", expected: "b\r"},
+		{newline: "\u2028", expected: "e"},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "a\nb\rc\r\nd\u2028e")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs([]string{
+			"extract",
+			"-i", input,
+			"-o", output,
+			"-s", "2",
+			"-e", "2",
+			"-l",
+			"--newline", tt.newline,
+		})
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, test.ReadString(t, output), tt.newline)
+	}
+}
+
+func TestExtractCmd_Newline_Error(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"-s", "1", "-b", "--newline", "cr"},
+			expected: "--newline can only be specified with -l",
+		},
+		{
+			args:     []string{"-s", "1", "-l", "--newline", ""},
+			expected: "newline must not be empty",
+		},
+		{
+			args:     []string{"-s", "1", "-l", "--newline", `\x`},
+			expected: `could not parse value \x of flag newline: invalid syntax`,
+		},
+		{
+			args:     []string{"-s", "1", "-l", "--newline", "lfcr"},
+			expected: "unknown newline: lfcr (lf, cr, crlf, any, unicode, a single character or a string with escape sequences)",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"extract", "-i", input, "-o", output}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		assert.EqualError(t, err, tt.expected)
+	}
+}
+
+func TestExtractCmd_Char_Grapheme(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "🇯🇵👨\u200d👩\u200d👧🇺🇸")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-o", output,
+		"-s", "-2",
+		"-e", "-2",
+		"-c",
+		"--char-unit", "grapheme",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "👨\u200d👩\u200d👧", test.ReadString(t, output))
+}
+
+func TestExtractCmd_CharUnit_NotChar(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-o", output,
+		"-s", "1",
+		"-b",
+		"--char-unit", "grapheme",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	assert.EqualError(t, err, "--char-unit can only be specified with -c")
+}
+
+func TestExtractCmd_Width(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "12あいうえお")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-o", output,
+		"-s", "3",
+		"-e", "8",
+		"-w",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "あいう", test.ReadString(t, output))
+}
+
+func TestExtractCmd_Width_Ranges(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "あいうえお")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-o", output,
+		"--ranges", "2-6,9-",
+		"-w",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "いうお", test.ReadString(t, output))
+}
+
+func TestExtractCmd_Width_AmbiguousWidth(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "①②③")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"extract",
+		"-i", input,
+		"-o", output,
+		"-s", "3",
+		"-w",
+		"--ambiguous-width", "2",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "②③", test.ReadString(t, output))
+}
+
+func TestExtractCmd_Width_Error(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"-c", "--ambiguous-width", "2"},
+			expected: "--ambiguous-width can only be specified with -w",
+		},
+		{
+			args:     []string{"-w", "--ambiguous-width", "3"},
+			expected: "ambiguous width must be 1 or 2",
+		},
+		{
+			args:     []string{"-w", "-l"},
+			expected: "specify one of the following: -b, -c, -l, -w",
+		},
+		{
+			args:     []string{"-w", "--fields", "1"},
+			expected: "--fields can only be used with -l",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"extract", "-i", input, "-o", output}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		assert.EqualError(t, err, tt.expected)
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/onozaty/filep/glob"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+// ディレクトリをたどる際に、対象とするファイルを絞り込むためのフィルタ
+type pathFilter struct {
+	includes    []*glob.Pattern
+	excludes    []*glob.Pattern
+	excludeDirs []*glob.Pattern
+}
+
+func getFlagPathFilter(f *pflag.FlagSet) (*pathFilter, error) {
+
+	includes, err := getFlagPatterns(f, "include")
+	if err != nil {
+		return nil, err
+	}
+
+	excludes, err := getFlagPatterns(f, "exclude")
+	if err != nil {
+		return nil, err
+	}
+
+	excludeDirs, err := getFlagPatterns(f, "exclude-dir")
+	if err != nil {
+		return nil, err
+	}
+
+	return &pathFilter{
+		includes:    includes,
+		excludes:    excludes,
+		excludeDirs: excludeDirs,
+	}, nil
+}
+
+func getFlagPatterns(f *pflag.FlagSet, name string) ([]*glob.Pattern, error) {
+
+	values, _ := f.GetStringArray(name)
+
+	patterns := []*glob.Pattern{}
+	for _, value := range values {
+
+		// / を含まないパターンは、どの階層のファイル名にもマッチさせる
+		pattern := filepath.ToSlash(value)
+		if !strings.Contains(strings.Trim(pattern, "/"), "/") {
+			pattern = "**/" + pattern
+		}
+
+		compiled, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "pattern %s specified in --%s is invalid", value, name)
+		}
+
+		patterns = append(patterns, compiled)
+	}
+
+	return patterns, nil
+}
+
+// 入力ディレクトリからの相対パスで、ファイルが対象となるかを判定します。
+func (f *pathFilter) matchFile(relPath string) bool {
+
+	relPath = filepath.ToSlash(relPath)
+
+	if len(f.includes) != 0 && !matchAny(f.includes, relPath) {
+		return false
+	}
+
+	return !matchAny(f.excludes, relPath)
+}
+
+// 入力ディレクトリからの相対パスで、ディレクトリ配下をたどるかを判定します。
+func (f *pathFilter) matchDir(relPath string) bool {
+
+	return !matchAny(f.excludeDirs, filepath.ToSlash(relPath))
+}
+
+func matchAny(patterns []*glob.Pattern, name string) bool {
+
+	for _, pattern := range patterns {
+		if pattern.Match(name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,27 +1,274 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
-func handle(inputPath string, outputPath string, process func(inputFilePath string, outputFilePath string) error, recursive bool) error {
+// 入力の内容を処理して出力に書き込みます。
+type processFunc func(input io.Reader, output io.Writer) (processResult, error)
+
+// 処理ごとの結果
+type processResult struct {
+	// 置換件数(置換以外の処理ではnil)
+	replacements *int
+	// 入力と出力の量(--reportの場合のみ)
+	inputStats  *textStats
+	outputStats *textStats
+}
+
+// 1ファイル分の処理結果
+type fileResult struct {
+	processResult
+	inputPath   string
+	outputPath  string
+	inputBytes  int64
+	outputBytes int64
+	changed     bool
+	duration    time.Duration
+	// 失敗した場合のエラー
+	err error
+	// dry-runの場合のみ保持する入力と出力の内容
+	inputContents  []byte
+	outputContents []byte
+}
+
+type handleOptions struct {
+	recursive    bool
+	inPlace      bool
+	backupSuffix string
+	filter       *pathFilter
+	jobs         int
+	dryRun       bool
+	// dry-runの結果を出力する処理
+	dryRunPrinter func(w io.Writer, result fileResult) error
+	keepGoing     bool
+	report        *reporter
+	stdin         io.Reader
+	stdout        io.Writer
+	stderr        io.Writer
+	// --in-placeで、内容を書き直さずにファイル自体を切り詰める場合に、残すバイト数を求める処理
+	// (truncateの場合のみで、切り詰められない場合はfalseを返す)
+	headSize func(input *os.File) (int64, bool, error)
+}
+
+func addHandleFlags(f *pflag.FlagSet) {
+
+	f.BoolP("recursive", "", false, "Recursively traverse the input dir.")
+	f.BoolP("in-place", "", false, "Edit files in place.")
+	f.StringP("backup-suffix", "", "", "Suffix of backup files when editing in place.")
+	f.StringArrayP("include", "", []string{}, "Glob pattern of files to process in the input dir. (repeatable)")
+	f.StringArrayP("exclude", "", []string{}, "Glob pattern of files to skip in the input dir. (repeatable)")
+	f.StringArrayP("exclude-dir", "", []string{}, "Glob pattern of dirs to skip in the input dir. (repeatable)")
+	f.IntP("jobs", "j", 0, "Number of files processed in parallel. (default number of CPUs)")
+	f.BoolP("dry-run", "", false, "Show what would be changed without writing files.")
+	f.BoolP("keep-going", "", false, "Continue processing other files when an error occurs.")
+	f.StringP("report", "", "", "Output a report of the results of each file to the specified path.")
+	f.StringP("report-format", "", reportFormatJSON, "Format of the report. (json or jsonl)")
+}
+
+func getHandleOptions(cmd *cobra.Command) (handleOptions, error) {
+
+	f := cmd.Flags()
+
+	outputPath, _ := f.GetString("output")
+	recursive, _ := f.GetBool("recursive")
+	inPlace, _ := f.GetBool("in-place")
+	backupSuffix, _ := f.GetString("backup-suffix")
+	jobs, _ := f.GetInt("jobs")
+	dryRun, _ := f.GetBool("dry-run")
+	keepGoing, _ := f.GetBool("keep-going")
+	reportPath, _ := f.GetString("report")
+	reportFormat, _ := f.GetString("report-format")
+	encoding, _ := f.GetString("encoding")
+
+	if inPlace && outputPath != "" {
+		return handleOptions{}, fmt.Errorf("--output and --in-place cannot be specified together")
+	}
+	if !inPlace && outputPath == "" {
+		return handleOptions{}, fmt.Errorf("--output or --in-place must be specified")
+	}
+	if !inPlace && backupSuffix != "" {
+		return handleOptions{}, fmt.Errorf("--backup-suffix can only be specified with --in-place")
+	}
+
+	if jobs < 0 {
+		return handleOptions{}, fmt.Errorf("jobs must be greater than or equal to 0")
+	}
+	if jobs == 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	filter, err := getFlagPathFilter(f)
+	if err != nil {
+		return handleOptions{}, err
+	}
+
+	var report *reporter
+	if reportPath != "" {
+		report, err = newReporter(reportPath, reportFormat, encoding)
+		if err != nil {
+			return handleOptions{}, err
+		}
+	} else if f.Changed("report-format") {
+		return handleOptions{}, fmt.Errorf("--report-format can only be specified with --report")
+	}
+
+	return handleOptions{
+		recursive:     recursive,
+		inPlace:       inPlace,
+		backupSuffix:  backupSuffix,
+		filter:        filter,
+		jobs:          jobs,
+		dryRun:        dryRun,
+		dryRunPrinter: printDryRunResult,
+		stdin:         cmd.InOrStdin(),
+		stdout:        cmd.OutOrStdout(),
+		stderr:        cmd.ErrOrStderr(),
+		keepGoing:     keepGoing,
+		report:        report,
+	}, nil
+}
+
+func handle(inputPath string, outputPath string, process processFunc, options handleOptions) (err error) {
+
+	if options.report != nil {
+		process = options.report.countingProcess(process)
+
+		// 処理に失敗した場合も、それまでの結果をレポートとして出力
+		defer func() {
+			if reportErr := options.report.write(); reportErr != nil && err == nil {
+				err = reportErr
+			}
+		}()
+	}
+
+	if inputPath == stdioPath || outputPath == stdioPath {
+		return handleStdio(inputPath, outputPath, process, options)
+	}
 
 	inputInfo, err := os.Stat(inputPath)
 	if err != nil {
 		return err
 	}
 
+	if options.inPlace {
+		// 入力自体を書き換える
+		outputPath = inputPath
+	}
+
 	if !inputInfo.IsDir() {
 		// ファイル指定
-		return handleFile(inputPath, outputPath, process)
+		result, err := handleFile(inputPath, outputPath, process, options)
+		if err != nil {
+			options.report.record(failedResult(inputPath, outputPath, result, err))
+			return err
+		}
+		options.report.record(result)
+		return emitResult(result, options)
 	} else {
 		// ディレクトリ指定
-		return handleFiles(inputPath, outputPath, process, recursive)
+		return handleFiles(inputPath, outputPath, process, options)
+	}
+}
+
+// 標準入力、標準出力を表すパス
+const stdioPath = "-"
+
+func handleStdio(inputPath string, outputPath string, process processFunc, options handleOptions) error {
+
+	if options.inPlace {
+		return fmt.Errorf("--in-place cannot be used with standard input")
+	}
+
+	input := options.stdin
+	if inputPath != stdioPath {
+		inputInfo, err := os.Stat(inputPath)
+		if err != nil {
+			return err
+		}
+		if inputInfo.IsDir() {
+			return fmt.Errorf("standard output cannot be used when the input is a directory")
+		}
+
+		inputFile, err := os.Open(inputPath)
+		if err != nil {
+			return err
+		}
+		defer inputFile.Close()
+
+		input = inputFile
+	}
+
+	start := time.Now()
+
+	var result fileResult
+	var err error
+	switch {
+	case options.dryRun:
+		result, err = dryRun(input, inputPath, outputPath, process)
+	case outputPath == stdioPath:
+		var processResult processResult
+		processResult, err = process(input, options.stdout)
+		result = fileResult{processResult: processResult, inputPath: inputPath, outputPath: outputPath}
+	default:
+		result, err = processFile(input, inputPath, outputPath, process)
+	}
+	result.duration = time.Since(start)
+	if err != nil {
+		options.report.record(failedResult(inputPath, outputPath, result, err))
+		return err
+	}
+
+	options.report.record(result)
+	return emitResult(result, options)
+}
+
+func handleFiles(inputDirPath string, outputDirPath string, process processFunc, options handleOptions) error {
+
+	// 先に対象ファイルを洗い出し(出力先のディレクトリもここで作成)、その後で並列に処理
+	tasks := []fileTask{}
+	if err := collectFileTasks(inputDirPath, outputDirPath, "", options, &tasks); err != nil {
+		return err
+	}
+
+	return runFileTasks(tasks, process, options)
+}
+
+type fileTask struct {
+	inputFilePath  string
+	outputFilePath string
+	// ディレクトリをたどる際に発生したエラー(--keep-goingの場合のみ)
+	err error
+}
+
+func collectFileTasks(inputDirPath string, outputDirPath string, relDirPath string, options handleOptions, tasks *[]fileTask) error {
+
+	err := prepareDir(inputDirPath, outputDirPath, relDirPath, options, tasks)
+	if err != nil && options.keepGoing {
+		// 処理を続ける場合、エラーはそのディレクトリのタスクとして記録しておく
+		*tasks = append(*tasks, fileTask{
+			inputFilePath:  inputDirPath,
+			outputFilePath: outputDirPath,
+			err:            err,
+		})
+		return nil
 	}
+
+	return err
 }
 
-func handleFiles(inputDirPath string, outputDirPath string, process func(inputFilePath string, outputFilePath string) error, recursive bool) error {
+func prepareDir(inputDirPath string, outputDirPath string, relDirPath string, options handleOptions, tasks *[]fileTask) error {
 
 	entries, err := os.ReadDir(inputDirPath)
 	if err != nil {
@@ -31,22 +278,34 @@ func handleFiles(inputDirPath string, outputDirPath string, process func(inputFi
 	// 出力先のディレクトリが無かったら作っておく
 	_, err = os.Stat(outputDirPath)
 	if os.IsNotExist(err) {
-		if err := os.Mkdir(outputDirPath, os.ModePerm); err != nil {
-			return err
+		// dry-runの場合は何も作らない
+		if !options.dryRun {
+			if err := os.Mkdir(outputDirPath, os.ModePerm); err != nil {
+				return err
+			}
 		}
 	} else if err != nil {
 		return err
 	}
 
 	for _, entry := range entries {
+		// フィルタは入力ディレクトリからの相対パスで判定
+		relPath := filepath.Join(relDirPath, entry.Name())
+
 		if !entry.IsDir() {
-			err := handleFile(filepath.Join(inputDirPath, entry.Name()), filepath.Join(outputDirPath, entry.Name()), process)
-			if err != nil {
-				return err
+			if !options.filter.matchFile(relPath) {
+				continue
 			}
-		} else if recursive {
+			*tasks = append(*tasks, fileTask{
+				inputFilePath:  filepath.Join(inputDirPath, entry.Name()),
+				outputFilePath: filepath.Join(outputDirPath, entry.Name()),
+			})
+		} else if options.recursive {
 			// ディレクトリかつ再帰的にたどる場合
-			if err := handleFiles(filepath.Join(inputDirPath, entry.Name()), filepath.Join(outputDirPath, entry.Name()), process, recursive); err != nil {
+			if !options.filter.matchDir(relPath) {
+				continue
+			}
+			if err := collectFileTasks(filepath.Join(inputDirPath, entry.Name()), filepath.Join(outputDirPath, entry.Name()), relPath, options, tasks); err != nil {
 				return err
 			}
 		}
@@ -55,7 +314,393 @@ func handleFiles(inputDirPath string, outputDirPath string, process func(inputFi
 	return nil
 }
 
-func handleFile(inputFilePath string, outputFilePath string, process func(inputPath string, outputPath string) error) error {
+func runFileTasks(tasks []fileTask, process processFunc, options handleOptions) error {
+
+	errs := make([]error, len(tasks))
+
+	// エラーとなったタスクの最小のインデックス
+	// これより後ろのタスクは実行しないが、前のタスクは必ず実行することで
+	// 並列数に関わらず、順番に処理した場合と同じエラーを返すようにする
+	// (--keep-goingの場合はエラーがあっても全て実行する)
+	var firstFailed atomic.Int64
+	firstFailed.Store(int64(len(tasks)))
+
+	// 処理結果は完了順ではなくタスクの順番で出力する
+	emitter := newOrderedEmitter(len(tasks), options)
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for range options.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if int64(i) > firstFailed.Load() {
+					continue
+				}
+
+				var result fileResult
+				var err error
+				if tasks[i].err != nil {
+					err = tasks[i].err
+				} else {
+					result, err = handleFile(tasks[i].inputFilePath, tasks[i].outputFilePath, process, options)
+				}
+
+				if err != nil {
+					errs[i] = err
+					result = failedResult(tasks[i].inputFilePath, tasks[i].outputFilePath, result, err)
+				}
+				emitter.complete(i, result)
+
+				if err != nil && !options.keepGoing {
+					for {
+						current := firstFailed.Load()
+						if int64(i) >= current || firstFailed.CompareAndSwap(current, int64(i)) {
+							break
+						}
+					}
+				}
+			}
+		}()
+	}
+
+	for i := range tasks {
+		if int64(i) > firstFailed.Load() {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if options.keepGoing {
+		if err := reportFailures(tasks, errs, options); err != nil {
+			return err
+		}
+	} else {
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return emitter.err
+}
+
+// 並列に処理した結果を、タスクの順番通りに出力するためのもの
+type orderedEmitter struct {
+	mu      sync.Mutex
+	results []*fileResult
+	next    int
+	options handleOptions
+	// 出力時に発生したエラー
+	err error
+	// エラーとなったものを記録して、以降は出力しない状態か
+	stopped bool
+}
+
+func newOrderedEmitter(size int, options handleOptions) *orderedEmitter {
+	return &orderedEmitter{
+		results: make([]*fileResult, size),
+		options: options,
+	}
+}
+
+func (e *orderedEmitter) complete(index int, result fileResult) {
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.results[index] = &result
+
+	// 前のタスクが全て終わっているものから順に出力
+	for e.err == nil && !e.stopped && e.next < len(e.results) && e.results[e.next] != nil {
+		result := e.results[e.next]
+		e.options.report.record(*result)
+		if result.err != nil && !e.options.keepGoing {
+			// エラーとなったもの以降は出力しない
+			// (後から完了したものによって、同じ結果が再び記録されないように)
+			e.stopped = true
+			return
+		}
+		if result.err == nil {
+			e.err = emitResult(*result, e.options)
+		}
+		e.results[e.next] = nil
+		e.next++
+	}
+}
+
+// 失敗したファイルの一覧を出力し、エラーとして返します。
+func reportFailures(tasks []fileTask, errs []error, options handleOptions) error {
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(options.stderr, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tERROR")
+	for i, err := range errs {
+		if err != nil {
+			fmt.Fprintf(w, "%s\t%s\n", tasks[i].inputFilePath, err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%d of %d files failed", failed, len(tasks))
+}
+
+// 失敗したファイルの結果を作成します。
+func failedResult(inputPath string, outputPath string, result fileResult, err error) fileResult {
+
+	return fileResult{
+		inputPath:  inputPath,
+		outputPath: outputPath,
+		duration:   result.duration,
+		err:        err,
+	}
+}
+
+func emitResult(result fileResult, options handleOptions) error {
+
+	if options.dryRun {
+		return options.dryRunPrinter(options.stdout, result)
+	}
+
+	return nil
+}
+
+func handleFile(inputFilePath string, outputFilePath string, process processFunc, options handleOptions) (fileResult, error) {
+
+	start := time.Now()
+	result, err := handleFileContents(inputFilePath, outputFilePath, process, options)
+	result.duration = time.Since(start)
+
+	return result, err
+}
+
+func handleFileContents(inputFilePath string, outputFilePath string, process processFunc, options handleOptions) (fileResult, error) {
+
+	if !options.inPlace {
+		// 出力先が入力と同じファイルだと、読み込む前に内容が消えてしまうのでエラーに
+		if same, err := isSameFile(inputFilePath, outputFilePath); err != nil {
+			return fileResult{}, err
+		} else if same {
+			return fileResult{}, fmt.Errorf("input and output are the same file: %s (use --in-place)", inputFilePath)
+		}
+	}
+
+	input, err := os.Open(inputFilePath)
+	if err != nil {
+		return fileResult{}, err
+	}
+	defer input.Close()
+
+	if options.dryRun {
+		return dryRun(input, inputFilePath, outputFilePath, process)
+	}
+
+	if options.inPlace {
+		// レポートには内容が必要となるので、切り詰めるのはレポートが無い場合のみ
+		if options.headSize != nil && options.report == nil {
+			size, ok, err := options.headSize(input)
+			if err != nil {
+				return fileResult{}, err
+			}
+			if ok {
+				return truncateFileInPlace(input, inputFilePath, size, options.backupSuffix)
+			}
+
+			// 切り詰められない場合は、最初から読み直して書き直す
+			if _, err := input.Seek(0, io.SeekStart); err != nil {
+				return fileResult{}, err
+			}
+		}
+		return handleFileInPlace(input, inputFilePath, process, options.backupSuffix)
+	}
+
+	return processFile(input, inputFilePath, outputFilePath, process)
+}
+
+func processFile(input io.Reader, inputPath string, outputFilePath string, process processFunc) (fileResult, error) {
+
+	output, err := os.Create(outputFilePath)
+	if err != nil {
+		return fileResult{}, err
+	}
+
+	processResult, err := process(input, output)
+	if err != nil {
+		output.Close()
+		return fileResult{}, err
+	}
+
+	if err := output.Close(); err != nil {
+		return fileResult{}, err
+	}
+
+	return fileResult{
+		processResult: processResult,
+		inputPath:     inputPath,
+		outputPath:    outputFilePath,
+	}, nil
+}
+
+func handleFileInPlace(input *os.File, filePath string, process processFunc, backupSuffix string) (fileResult, error) {
+
+	info, err := input.Stat()
+	if err != nil {
+		return fileResult{}, err
+	}
+
+	// 同じディレクトリに一時ファイルとして出力し、最後に置き換える
+	// (同じファイルシステム上でのリネームとなるのでアトミックに置き換わる)
+	temp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return fileResult{}, err
+	}
+	tempPath := temp.Name()
+
+	replaced := false
+	defer func() {
+		if !replaced {
+			os.Remove(tempPath)
+		}
+	}()
+
+	processResult, err := process(input, temp)
+	if err != nil {
+		temp.Close()
+		return fileResult{}, err
+	}
+	if err := temp.Close(); err != nil {
+		return fileResult{}, err
+	}
+	// Windowsでは開いたままのファイルを置き換えられないので先に閉じておく
+	input.Close()
+
+	// 一時ファイルは元ファイルとパーミッションが異なるので合わせておく
+	if err := os.Chmod(tempPath, info.Mode().Perm()); err != nil {
+		return fileResult{}, err
+	}
+
+	if backupSuffix != "" {
+		if err := backupFile(filePath, filePath+backupSuffix); err != nil {
+			return fileResult{}, err
+		}
+	}
+
+	if err := os.Rename(tempPath, filePath); err != nil {
+		return fileResult{}, err
+	}
+	replaced = true
+
+	return fileResult{
+		processResult: processResult,
+		inputPath:     filePath,
+		outputPath:    filePath,
+	}, nil
+}
+
+// ファイル自体を指定の長さに切り詰めます。
+// 残す部分を書き直さないので、大きなファイルでも速く処理できます。
+func truncateFileInPlace(input *os.File, filePath string, size int64, backupSuffix string) (fileResult, error) {
+
+	info, err := input.Stat()
+	if err != nil {
+		return fileResult{}, err
+	}
+	input.Close()
+
+	if backupSuffix != "" {
+		// 元ファイル自体を切り詰めるので、ハードリンクではなくコピーしておく
+		backupPath := filePath + backupSuffix
+		if err := os.Remove(backupPath); err != nil && !os.IsNotExist(err) {
+			return fileResult{}, err
+		}
+		if err := copyFile(filePath, backupPath); err != nil {
+			return fileResult{}, err
+		}
+	}
+
+	if size < info.Size() {
+		if err := os.Truncate(filePath, size); err != nil {
+			return fileResult{}, err
+		}
+	}
+
+	return fileResult{
+		inputPath:  filePath,
+		outputPath: filePath,
+	}, nil
+}
+
+func backupFile(filePath string, backupPath string) error {
+
+	if err := os.Remove(backupPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// ハードリンクで済めばコピー不要
+	// (元ファイルはリネームで置き換えられるので、バックアップ側は元の内容のまま残る)
+	if err := os.Link(filePath, backupPath); err == nil {
+		return nil
+	}
+
+	return copyFile(filePath, backupPath)
+}
+
+func copyFile(srcPath string, dstPath string) error {
+
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+
+	return dst.Close()
+}
+
+func isSameFile(path1 string, path2 string) (bool, error) {
+
+	info1, err := os.Stat(path1)
+	if err != nil {
+		return false, err
+	}
+
+	info2, err := os.Stat(path2)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
 
-	return process(inputFilePath, outputFilePath)
+	return os.SameFile(info1, info2), nil
 }
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedEmitter_Error(t *testing.T) {
+
+	// ARRANGE
+	report, err := newReporter(filepath.Join(t.TempDir(), "report.json"), reportFormatJSON, "UTF-8")
+	require.NoError(t, err)
+
+	emitter := newOrderedEmitter(4, handleOptions{report: report})
+
+	// ACT
+	emitter.complete(1, fileResult{inputPath: "1", err: fmt.Errorf("failed")})
+	emitter.complete(0, fileResult{inputPath: "0"})
+	// エラーより後のものが完了しても、記録されないこと
+	emitter.complete(3, fileResult{inputPath: "3"})
+	emitter.complete(2, fileResult{inputPath: "2"})
+
+	// ASSERT
+	// エラーとなったものまでが、1回ずつ記録されること
+	paths := []string{}
+	for _, record := range report.records {
+		paths = append(paths, record.InputPath)
+	}
+	assert.Equal(t, []string{"0", "1"}, paths)
+	require.NotNil(t, report.records[1].Error)
+	assert.Equal(t, "failed", *report.records[1].Error)
+}
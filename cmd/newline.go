@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/onozaty/filep/extract/extractor"
+
+	"github.com/spf13/pflag"
+)
+
+func addNewlineFlag(f *pflag.FlagSet, target string) {
+
+	f.StringP("newline", "", "lf", "Newline for "+target+". (lf, cr, crlf, any, unicode, a single character or a string with escape sequences)")
+}
+
+func getFlagNewline(f *pflag.FlagSet) (extractor.Newline, error) {
+
+	str, _ := f.GetString("newline")
+
+	switch strings.ToLower(str) {
+	case "lf":
+		return extractor.NewlineLF, nil
+	case "cr":
+		return extractor.NewlineCR, nil
+	case "crlf":
+		return extractor.NewlineCRLF, nil
+	case "any":
+		return extractor.NewlineAny, nil
+	case "unicode":
+		return extractor.NewlineUnicode, nil
+	}
+
+	// それ以外は任意の文字列として扱う(\x00のようにエスケープシーケンスで指定)
+	// 名前の打ち間違いを任意の文字列と扱わないように、1文字の場合以外はエスケープシーケンスを含むものに限る
+	if str != "" && !strings.Contains(str, `\`) && utf8.RuneCountInString(str) != 1 {
+		return extractor.Newline{}, fmt.Errorf("unknown newline: %s (lf, cr, crlf, any, unicode, a single character or a string with escape sequences)", str)
+	}
+
+	terminator, err := getFlagEscapedString(f, "newline", true)
+	if err != nil {
+		return extractor.Newline{}, err
+	}
+
+	return extractor.NewCustomNewline(terminator)
+}
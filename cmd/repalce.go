@@ -2,12 +2,15 @@ package cmd
 
 import (
 	"fmt"
-	"os"
+	"io"
 	"regexp"
-	"strconv"
+	"unicode/utf8"
 
+	"github.com/onozaty/filep/escape"
+	"github.com/onozaty/filep/replace/diff"
 	"github.com/onozaty/filep/replace/encoder"
 	"github.com/onozaty/filep/replace/replacer"
+	"github.com/onozaty/filep/replace/rule"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -41,11 +44,45 @@ func newReplaceCmd() *cobra.Command {
 				return err
 			}
 
-			recursive, _ := cmd.Flags().GetBool("recursive")
+			rulesPath, _ := cmd.Flags().GetString("rules")
 			encoding, _ := cmd.Flags().GetString("encoding")
+			showDiff, _ := cmd.Flags().GetBool("diff")
+			diffContext, _ := cmd.Flags().GetInt("diff-context")
+			stream, _ := cmd.Flags().GetBool("stream")
+			maxMatchLength, _ := cmd.Flags().GetInt("max-match-length")
 
-			if targetStr == "" && targetRegex == "" {
-				return fmt.Errorf("--regex or --string must be specified")
+			handleOptions, err := getHandleOptions(cmd)
+			if err != nil {
+				return err
+			}
+
+			if rulesPath != "" {
+				// エスケープシーケンスを使うかは、ルールごとにルールファイルで指定する
+				if cmd.Flags().Changed("regex") || cmd.Flags().Changed("string") || cmd.Flags().Changed("replacement") || cmd.Flags().Changed("escape") {
+					return fmt.Errorf("--rules cannot be specified with --regex, --string, --replacement or --escape")
+				}
+			} else {
+				if targetStr == "" && targetRegex == "" {
+					return fmt.Errorf("--regex or --string must be specified")
+				}
+				if !cmd.Flags().Changed("replacement") {
+					return fmt.Errorf("--replacement must be specified")
+				}
+			}
+
+			if maxMatchLength < 0 {
+				return fmt.Errorf("max-match-length must be greater than or equal to 0")
+			}
+			if cmd.Flags().Changed("max-match-length") && !stream {
+				return fmt.Errorf("--max-match-length can only be specified with --stream")
+			}
+
+			if diffContext < 0 {
+				return fmt.Errorf("diff-context must be greater than or equal to 0")
+			}
+			if showDiff {
+				// 差分の表示のみで書き込みは行わない
+				handleOptions.dryRun = true
 			}
 
 			var regex *regexp.Regexp
@@ -63,27 +100,36 @@ func newReplaceCmd() *cobra.Command {
 				inputPath,
 				outputPath,
 				replaceCondition{
-					targetRegex: regex,
-					targetStr:   targetStr,
-					replacement: replacement,
+					targetRegex:    regex,
+					targetStr:      targetStr,
+					replacement:    replacement,
+					rulesPath:      rulesPath,
+					stream:         stream,
+					maxMatchLength: maxMatchLength,
+					binary:         encoder.IsBinary(encoding),
 				},
 				encoding,
-				recursive)
+				showDiff,
+				diffContext,
+				handleOptions)
 		},
 	}
 
 	replaceCmd.Flags().StringP("input", "i", "", "Input file/dir path.")
 	replaceCmd.MarkFlagRequired("input")
 	replaceCmd.Flags().StringP("output", "o", "", "Output file/dir path.")
-	replaceCmd.MarkFlagRequired("output")
 
 	replaceCmd.Flags().StringP("regex", "r", "", "Target regex.")
 	replaceCmd.Flags().StringP("string", "s", "", "Target string.")
 	replaceCmd.Flags().StringP("replacement", "t", "", "Replacement.")
-	replaceCmd.MarkFlagRequired("replacement")
+	replaceCmd.Flags().StringP("rules", "", "", "Rules file path (.json or .tsv) of replacements applied in order.")
 
 	replaceCmd.Flags().BoolP("escape", "", false, "Enable escape sequence.")
-	replaceCmd.Flags().BoolP("recursive", "", false, "Recursively traverse the input dir.")
+	replaceCmd.Flags().BoolP("stream", "", false, "Replace while reading the input without loading the whole file into memory.")
+	replaceCmd.Flags().IntP("max-match-length", "", 0, "Maximum match length (in characters) of regex with --stream. (default 0 means matching within each line)")
+	replaceCmd.Flags().BoolP("diff", "", false, "Show a unified diff of the changes without writing files.")
+	replaceCmd.Flags().IntP("diff-context", "", 3, "Number of context lines in the diff.")
+	addHandleFlags(replaceCmd.Flags())
 	replaceCmd.Flags().StringP("encoding", "", "UTF-8", "Encoding.")
 
 	return replaceCmd
@@ -93,73 +139,250 @@ type replaceCondition struct {
 	targetRegex *regexp.Regexp
 	targetStr   string
 	replacement string
+	rulesPath   string
+	// ストリームで置換する場合の設定
+	stream         bool
+	maxMatchLength int
+	// binaryの場合は全体が1行となり、行ごとに置換すると全体を保持することになるため、
+	// 正規表現には--max-match-lengthを必須とする
+	binary bool
 }
 
-func runReplace(inputPath string, outputPath string, condition replaceCondition, encoding string, recursive bool) error {
+func runReplace(inputPath string, outputPath string, condition replaceCondition, encoding string, showDiff bool, diffContext int, options handleOptions) error {
 
 	encoder, err := encoder.NewEncoder(encoding)
 	if err != nil {
 		return err
 	}
 
-	replacer := newReplacer(condition)
+	if showDiff {
+		options.dryRunPrinter = func(w io.Writer, result fileResult) error {
+			return printDiff(w, result, encoder, diffContext)
+		}
+	}
+
+	var process processFunc
+	if condition.stream {
+		newStreamReplacer, err := newStreamReplacerFactory(condition)
+		if err != nil {
+			return err
+		}
 
-	process := func(inputFilePath string, outputFilePath string) error {
-		return replaceFile(inputFilePath, outputFilePath, replacer, encoder)
+		process = func(input io.Reader, output io.Writer) (processResult, error) {
+			// 置換途中の状態を持つため、ファイルごとに作成
+			replacer, err := newStreamReplacer()
+			if err != nil {
+				return processResult{}, err
+			}
+			return replaceStream(input, output, replacer, encoder)
+		}
+	} else {
+		replacer, err := newReplacer(condition)
+		if err != nil {
+			return err
+		}
+
+		process = func(input io.Reader, output io.Writer) (processResult, error) {
+			return replaceFile(input, output, replacer, encoder)
+		}
 	}
 
-	return handle(inputPath, outputPath, process, recursive)
+	return handle(inputPath, outputPath, process, options)
 }
 
-func replaceFile(inputFilePath string, outputFilePath string, replacer replacer.Replacer, encoder encoder.Encoder) error {
+func replaceFile(input io.Reader, output io.Writer, replacer replacer.Replacer, encoder encoder.Encoder) (processResult, error) {
 
-	inputBytes, err := os.ReadFile(inputFilePath)
+	inputBytes, err := io.ReadAll(input)
 	if err != nil {
-		return err
+		return processResult{}, err
 	}
 
 	inputContents, err := encoder.String(inputBytes)
 	if err != nil {
-		return err
+		return processResult{}, err
 	}
 
-	outputContents := replacer.Replace(inputContents)
+	outputContents, replacements := replacer.Replace(inputContents)
 
-	out, err := os.Create(outputFilePath)
+	encodedBytes, err := encoder.Bytes(outputContents)
 	if err != nil {
-		return err
+		return processResult{}, err
 	}
-	defer out.Close()
 
-	encodedBytes, err := encoder.Bytes(outputContents)
+	if _, err := output.Write(encodedBytes); err != nil {
+		return processResult{}, err
+	}
+
+	return processResult{replacements: &replacements}, nil
+}
+
+// ストリームで置換する際の読み込み単位
+const streamBufferSize = 64 * 1024
+
+func replaceStream(input io.Reader, output io.Writer, replacer replacer.StreamReplacer, encoder encoder.Encoder) (processResult, error) {
+
+	reader := encoder.NewReader(input)
+	writer := encoder.NewWriter(output)
+
+	replacements := 0
+	buf := make([]byte, streamBufferSize)
+	pending := 0
+	for {
+		n, err := reader.Read(buf[pending:])
+		if err != nil && err != io.EOF {
+			return processResult{}, err
+		}
+		n += pending
+
+		// 文字の途中で区切られている場合は、その文字を次の読み込みに持ち越す
+		end := n
+		if err == nil {
+			end = completeRunesLength(buf[:n])
+		}
+
+		replaced, count := replacer.Push(string(buf[:end]))
+		replacements += count
+		if _, err := io.WriteString(writer, replaced); err != nil {
+			return processResult{}, err
+		}
+
+		pending = copy(buf, buf[end:n])
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	replaced, count := replacer.Flush()
+	replacements += count
+	if _, err := io.WriteString(writer, replaced); err != nil {
+		return processResult{}, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return processResult{}, err
+	}
+
+	return processResult{replacements: &replacements}, nil
+}
+
+func completeRunesLength(b []byte) int {
+
+	// 末尾の文字の先頭を探し、その文字が揃っているかを確認
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if utf8.FullRune(b[i:]) {
+				return len(b)
+			}
+			return i
+		}
+	}
+
+	return len(b)
+}
+
+func printDiff(w io.Writer, result fileResult, encoder encoder.Encoder, context int) error {
+
+	if !result.changed {
+		return nil
+	}
+
+	// 指定されたエンコーディングで文字列にしたうえで比較
+	inputContents, err := encoder.String(result.inputContents)
+	if err != nil {
+		return err
+	}
+	outputContents, err := encoder.String(result.outputContents)
 	if err != nil {
 		return err
 	}
 
-	_, err = out.Write(encodedBytes)
+	_, err = io.WriteString(w, diff.Unified(result.inputPath, result.outputPath, inputContents, outputContents, context))
 	return err
 }
 
-func newReplacer(condition replaceCondition) replacer.Replacer {
+func newReplacer(condition replaceCondition) (replacer.Replacer, error) {
+
+	if condition.rulesPath != "" {
+		rules, err := rule.Load(condition.rulesPath)
+		if err != nil {
+			return nil, err
+		}
+
+		r, err := rule.NewReplacer(rules)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "rules file %s is invalid", condition.rulesPath)
+		}
+		return r, nil
+	}
 
 	if condition.targetRegex != nil {
-		return replacer.NewRegexpReplacer(condition.targetRegex, condition.replacement)
+		return replacer.NewRegexpReplacer(condition.targetRegex, condition.replacement), nil
+	}
+
+	return replacer.NewStringReplacer(condition.targetStr, condition.replacement), nil
+}
+
+func newStreamReplacerFactory(condition replaceCondition) (func() (replacer.StreamReplacer, error), error) {
+
+	if condition.rulesPath != "" {
+		rules, err := rule.Load(condition.rulesPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if condition.binary && condition.maxMatchLength == 0 {
+			for _, r := range rules {
+				if r.Type == rule.TypeRegex {
+					return nil, fmt.Errorf("--max-match-length must be specified to use regex with --stream and --encoding binary")
+				}
+			}
+		}
+
+		factory, err := rule.NewStreamReplacerFactory(rules, condition.maxMatchLength)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "rules file %s is invalid", condition.rulesPath)
+		}
+		return factory, nil
+	}
+
+	if condition.targetRegex != nil {
+		if condition.maxMatchLength == 0 {
+			if condition.binary {
+				return nil, fmt.Errorf("--max-match-length must be specified to use regex with --stream and --encoding binary")
+			}
+			return func() (replacer.StreamReplacer, error) {
+				return replacer.NewRegexpLineStreamReplacer(condition.targetRegex, condition.replacement), nil
+			}, nil
+		}
+
+		factory := func() (replacer.StreamReplacer, error) {
+			return replacer.NewRegexpWindowStreamReplacer(condition.targetRegex, condition.replacement, condition.maxMatchLength)
+		}
+
+		// 作成できることを確認しておく
+		if _, err := factory(); err != nil {
+			return nil, errors.WithMessage(err, "regular expression specified in --regex is invalid")
+		}
+		return factory, nil
 	}
 
-	return replacer.NewStringReplacer(condition.targetStr, condition.replacement)
+	return func() (replacer.StreamReplacer, error) {
+		return replacer.NewStringStreamReplacer(condition.targetStr, condition.replacement), nil
+	}, nil
 }
 
-func getFlagEscapedString(f *pflag.FlagSet, name string, escape bool) (string, error) {
+func getFlagEscapedString(f *pflag.FlagSet, name string, escapeSequence bool) (string, error) {
 
 	str, _ := f.GetString(name)
 
-	if !escape {
+	if !escapeSequence {
 		// エスケープ無しの場合
 		return str, nil
 	}
 
 	// \nのように指定されているものを、スケープ文字として扱えるように
-	unq, err := strconv.Unquote(`"` + str + `"`)
+	unq, err := escape.Unescape(str)
 	if err != nil {
 		return "", errors.Wrapf(err, "could not parse value %s of flag %s", str, name)
 	}
@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/onozaty/filep/test"
@@ -287,6 +292,35 @@ func TestReplaceCmd_Escape_String(t *testing.T) {
 	assert.Equal(t, "1\t2\t", replaced)
 }
 
+func TestReplaceCmd_Escape_Quote(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", `say "hi"`+"\n")
+	output := filepath.Join(d, "output.txt")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		// エスケープされていない「"」はそのままの文字として扱う
+		"-s", `"`,
+		"-t", `\t"`,
+		"--escape",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	replaced := test.ReadString(t, output)
+	assert.Equal(t, "say \t\"hi\t\"\n", replaced)
+}
+
 func TestReplaceCmd_Escape_Regex(t *testing.T) {
 
 	// ARRANGE
@@ -604,3 +638,1573 @@ func TestReplaceCmd_OutputNotFound(t *testing.T) {
 	require.True(t, ok)
 	assert.Equal(t, output, pathErr.Path)
 }
+
+func TestReplaceCmd_InPlace_File(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc\nabc\naa")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "x",
+		"--in-place",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	replaced := test.ReadString(t, input)
+	assert.Equal(t, "xbc\nxbc\nxx", replaced)
+
+	// 一時ファイルは残っていないこと
+	entries, err := os.ReadDir(d)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestReplaceCmd_InPlace_Dir_Backup(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateDir(t, d, "input")
+	test.CreateFileWriteString(t, input, "1.txt", "abc")
+
+	inputSub := test.CreateDir(t, input, "sub")
+	test.CreateFileWriteString(t, inputSub, "2.txt", "aaa")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "x",
+		"--in-place",
+		"--backup-suffix", ".bak",
+		"--recursive",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	assert.Equal(t, "xbc", test.ReadString(t, filepath.Join(input, "1.txt")))
+	assert.Equal(t, "abc", test.ReadString(t, filepath.Join(input, "1.txt.bak")))
+	assert.Equal(t, "xxx", test.ReadString(t, filepath.Join(inputSub, "2.txt")))
+	assert.Equal(t, "aaa", test.ReadString(t, filepath.Join(inputSub, "2.txt.bak")))
+}
+
+func TestReplaceCmd_InPlace_Error(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteBytes(t, d, "input.txt", []byte{0xFF})
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "xFF",
+		"-t", "zzz", // バイナリとして不正
+		"--in-place",
+		"--encoding", "binary",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.EqualError(t, err, "illegal hex string \"zzz\"")
+
+	// 元の内容のままで、一時ファイルも残っていないこと
+	assert.Equal(t, []byte{0xFF}, test.ReadBytes(t, input))
+
+	entries, err := os.ReadDir(d)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestReplaceCmd_SameFile(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "x",
+		"-o", input,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.EqualError(t, err, "input and output are the same file: "+input+" (use --in-place)")
+
+	// 元の内容は壊れていないこと
+	assert.Equal(t, "abc", test.ReadString(t, input))
+}
+
+func TestReplaceCmd_NoneOutputAndInPlace(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "x",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.EqualError(t, err, "--output or --in-place must be specified")
+}
+
+func TestReplaceCmd_OutputAndInPlace(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc")
+	output := filepath.Join(d, "output.txt")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "x",
+		"-o", output,
+		"--in-place",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.EqualError(t, err, "--output and --in-place cannot be specified together")
+}
+
+func TestReplaceCmd_BackupSuffixWithoutInPlace(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc")
+	output := filepath.Join(d, "output.txt")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "x",
+		"-o", output,
+		"--backup-suffix", ".bak",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.EqualError(t, err, "--backup-suffix can only be specified with --in-place")
+}
+
+func TestReplaceCmd_Dir_IncludeExclude(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateDir(t, d, "input")
+	test.CreateFileWriteString(t, input, "1.txt", "a")
+	test.CreateFileWriteString(t, input, "2.png", "a")
+	test.CreateFileWriteString(t, input, "3.txt", "a")
+
+	inputSub := test.CreateDir(t, input, "sub")
+	test.CreateFileWriteString(t, inputSub, "4.txt", "a")
+	test.CreateFileWriteString(t, inputSub, "5.log", "a")
+
+	output := test.CreateDir(t, d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "x",
+		"-o", output,
+		"--recursive",
+		"--include", "*.txt",
+		"--include", "sub/*.log",
+		"--exclude", "3.txt",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	assert.Equal(t, "x", test.ReadString(t, filepath.Join(output, "1.txt")))
+	assert.NoFileExists(t, filepath.Join(output, "2.png"))
+	assert.NoFileExists(t, filepath.Join(output, "3.txt"))
+	assert.Equal(t, "x", test.ReadString(t, filepath.Join(output, "sub", "4.txt")))
+	assert.Equal(t, "x", test.ReadString(t, filepath.Join(output, "sub", "5.log")))
+}
+
+func TestReplaceCmd_Dir_ExcludeDir(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateDir(t, d, "input")
+	test.CreateFileWriteString(t, input, "1.txt", "a")
+
+	inputGit := test.CreateDir(t, input, ".git")
+	test.CreateFileWriteString(t, inputGit, "HEAD", "a")
+
+	inputSub := test.CreateDir(t, input, "sub")
+	test.CreateFileWriteString(t, inputSub, "2.txt", "a")
+
+	inputSubGit := test.CreateDir(t, inputSub, ".git")
+	test.CreateFileWriteString(t, inputSubGit, "HEAD", "a")
+
+	inputSubVendor := test.CreateDir(t, inputSub, "vendor")
+	test.CreateFileWriteString(t, inputSubVendor, "3.txt", "a")
+
+	output := test.CreateDir(t, d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "x",
+		"-o", output,
+		"--recursive",
+		"--exclude-dir", ".git",
+		"--exclude-dir", "sub/vendor",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	assert.Equal(t, "x", test.ReadString(t, filepath.Join(output, "1.txt")))
+	assert.Equal(t, "x", test.ReadString(t, filepath.Join(output, "sub", "2.txt")))
+	assert.NoDirExists(t, filepath.Join(output, ".git"))
+	assert.NoDirExists(t, filepath.Join(output, "sub", ".git"))
+	assert.NoDirExists(t, filepath.Join(output, "sub", "vendor"))
+}
+
+func TestReplaceCmd_InvalidInclude(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateDir(t, d, "input")
+	output := test.CreateDir(t, d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "x",
+		"-o", output,
+		"--include", "[a",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.EqualError(t, err, "pattern [a specified in --include is invalid: syntax error in pattern")
+}
+
+func TestReplaceCmd_Dir_Jobs(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateDir(t, d, "input")
+	inputSub := test.CreateDir(t, input, "sub")
+	for i := range 50 {
+		test.CreateFileWriteString(t, input, fmt.Sprintf("%02d.txt", i), fmt.Sprintf("a%d", i))
+		test.CreateFileWriteString(t, inputSub, fmt.Sprintf("%02d.txt", i), fmt.Sprintf("aa%d", i))
+	}
+
+	output := filepath.Join(d, "output") // 存在しない
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "x",
+		"-o", output,
+		"--recursive",
+		"--jobs", "4",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	for i := range 50 {
+		assert.Equal(t, fmt.Sprintf("x%d", i), test.ReadString(t, filepath.Join(output, fmt.Sprintf("%02d.txt", i))))
+		assert.Equal(t, fmt.Sprintf("xx%d", i), test.ReadString(t, filepath.Join(output, "sub", fmt.Sprintf("%02d.txt", i))))
+	}
+}
+
+func TestReplaceCmd_Dir_Jobs_Error(t *testing.T) {
+
+	for range 10 {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateDir(t, d, "input")
+		for i := range 30 {
+			test.CreateFileWriteString(t, input, fmt.Sprintf("%02d.txt", i), "a")
+		}
+
+		// 出力先にディレクトリがあると書き込みに失敗する
+		output := test.CreateDir(t, d, "output")
+		test.CreateDir(t, output, "10.txt")
+		test.CreateDir(t, output, "20.txt")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs([]string{
+			"replace",
+			"-i", input,
+			"-s", "a",
+			"-t", "x",
+			"-o", output,
+			"--jobs", "8",
+		})
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		require.Error(t, err)
+
+		// 並列で処理しても、最初のファイルのエラーが返ること
+		pathErr, ok := err.(*os.PathError)
+		require.True(t, ok)
+		assert.Equal(t, filepath.Join(output, "10.txt"), pathErr.Path)
+
+		// エラーより前のファイルは処理されていること
+		for i := range 10 {
+			assert.Equal(t, "x", test.ReadString(t, filepath.Join(output, fmt.Sprintf("%02d.txt", i))))
+		}
+	}
+}
+
+func TestReplaceCmd_Dir_Jobs_Error_Report(t *testing.T) {
+
+	for range 10 {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateDir(t, d, "input")
+		for i := range 5 {
+			contents := "a"
+			if i > 2 {
+				// エラーより後のファイルは、エラーが記録された後に完了するように大きくしておく
+				contents = strings.Repeat("a", 8*1024*1024)
+			}
+			test.CreateFileWriteString(t, input, fmt.Sprintf("%02d.txt", i), contents)
+		}
+
+		// 出力先にディレクトリがあると書き込みに失敗する
+		output := test.CreateDir(t, d, "output")
+		test.CreateDir(t, output, "02.txt")
+
+		report := filepath.Join(d, "report.json")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs([]string{
+			"replace",
+			"-i", input,
+			"-s", "a",
+			"-t", "x",
+			"-o", output,
+			"--jobs", "5",
+			"--report", report,
+		})
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		require.Error(t, err)
+
+		// エラーとなったファイルまでが、1回ずつ記録されること
+		records := readReport(t, report)
+		require.Len(t, records, 3)
+		for i, record := range records {
+			assert.Equal(t, filepath.Join(input, fmt.Sprintf("%02d.txt", i)), record["input_path"])
+		}
+		assert.Equal(t, err.Error(), records[2]["error"])
+	}
+}
+
+func TestReplaceCmd_InvalidJobs(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateDir(t, d, "input")
+	output := test.CreateDir(t, d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "x",
+		"-o", output,
+		"--jobs", "-1",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.EqualError(t, err, "jobs must be greater than or equal to 0")
+}
+
+func TestReplaceCmd_Stdio(t *testing.T) {
+
+	// ARRANGE
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", "-",
+		"-s", "a",
+		"-t", "x",
+		"-o", "-",
+	})
+
+	rootCmd.SetIn(strings.NewReader("abc\nabc"))
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "xbc\nxbc", buf.String())
+}
+
+func TestReplaceCmd_Stdin_File(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	output := filepath.Join(d, "output.txt")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", "-",
+		"-s", "a",
+		"-t", "x",
+		"-o", output,
+	})
+
+	rootCmd.SetIn(strings.NewReader("abc"))
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "xbc", test.ReadString(t, output))
+}
+
+func TestReplaceCmd_File_Stdout(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "x",
+		"-o", "-",
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "xbc", buf.String())
+}
+
+func TestReplaceCmd_Dir_Stdout(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateDir(t, d, "input")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "x",
+		"-o", "-",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.EqualError(t, err, "standard output cannot be used when the input is a directory")
+}
+
+func TestReplaceCmd_Stdin_InPlace(t *testing.T) {
+
+	// ARRANGE
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", "-",
+		"-s", "a",
+		"-t", "x",
+		"--in-place",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.EqualError(t, err, "--in-place cannot be used with standard input")
+}
+
+func TestReplaceCmd_DryRun(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateDir(t, d, "input")
+	test.CreateFileWriteString(t, input, "1.txt", "abca")
+	test.CreateFileWriteString(t, input, "2.txt", "xyz")
+
+	inputSub := test.CreateDir(t, input, "sub")
+	test.CreateFileWriteString(t, inputSub, "3.txt", "aaa")
+
+	output := filepath.Join(d, "output") // 存在しない
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "xx",
+		"-o", output,
+		"--recursive",
+		"--dry-run",
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"changed: "+filepath.Join(input, "1.txt")+" (2 matches, 4 -> 6 bytes)\n"+
+			"unchanged: "+filepath.Join(input, "2.txt")+" (0 matches, 3 -> 3 bytes)\n"+
+			"changed: "+filepath.Join(input, "sub", "3.txt")+" (3 matches, 3 -> 6 bytes)\n",
+		buf.String())
+
+	// 出力先は作られないこと
+	assert.NoDirExists(t, output)
+}
+
+func TestReplaceCmd_DryRun_Jobs(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateDir(t, d, "input")
+	expected := ""
+	for i := range 100 {
+		name := fmt.Sprintf("%03d.txt", i)
+		test.CreateFileWriteString(t, input, name, "a")
+		expected += "changed: " + filepath.Join(input, name) + " (1 matches, 1 -> 1 bytes)\n"
+	}
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "b",
+		"--in-place",
+		"--dry-run",
+		"--jobs", "8",
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	// 並列で処理しても順番通りに出力されること
+	assert.Equal(t, expected, buf.String())
+
+	// 書き換えられていないこと
+	assert.Equal(t, "a", test.ReadString(t, filepath.Join(input, "000.txt")))
+}
+
+func TestReplaceCmd_Diff(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateDir(t, d, "input")
+	test.CreateFileWriteString(t, input, "1.txt", "1\n2\na\n4\n5\n6\n7\n")
+	test.CreateFileWriteString(t, input, "2.txt", "xyz\n") // 変更無し
+	test.CreateFileWriteString(t, input, "3.txt", "a")
+
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "b",
+		"-o", output,
+		"--diff",
+		"--diff-context", "1",
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"--- "+filepath.Join(input, "1.txt")+"\n"+
+			"+++ "+filepath.Join(output, "1.txt")+"\n"+
+			"@@ -2,3 +2,3 @@\n"+
+			" 2\n"+
+			"-a\n"+
+			"+b\n"+
+			" 4\n"+
+			"--- "+filepath.Join(input, "3.txt")+"\n"+
+			"+++ "+filepath.Join(output, "3.txt")+"\n"+
+			"@@ -1 +1 @@\n"+
+			"-a\n"+
+			"\\ No newline at end of file\n"+
+			"+b\n"+
+			"\\ No newline at end of file\n",
+		buf.String())
+
+	// 書き込みは行われないこと
+	assert.NoDirExists(t, output)
+}
+
+func TestReplaceCmd_Diff_SJIS(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteBytes(t, d, "input.txt", test.StringToByte(t, "あいう\nえお\n", japanese.ShiftJIS))
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "え",
+		"-t", "エ",
+		"--in-place",
+		"--diff",
+		"--encoding", "sjis",
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"--- "+input+"\n"+
+			"+++ "+input+"\n"+
+			"@@ -1,2 +1,2 @@\n"+
+			" あいう\n"+
+			"-えお\n"+
+			"+エお\n",
+		buf.String())
+
+	assert.Equal(t, "あいう\nえお\n", test.ByteToString(t, test.ReadBytes(t, input), japanese.ShiftJIS))
+}
+
+func TestReplaceCmd_InvalidDiffContext(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "a")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "b",
+		"--in-place",
+		"--diff",
+		"--diff-context", "-1",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.EqualError(t, err, "diff-context must be greater than or equal to 0")
+}
+
+func TestReplaceCmd_KeepGoing(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateDir(t, d, "input")
+	test.CreateFileWriteString(t, input, "1.txt", "a")
+	test.CreateFileWriteString(t, input, "2.txt", "a")
+	test.CreateFileWriteString(t, input, "3.txt", "a")
+	test.CreateFileWriteString(t, input, "4.txt", "a")
+
+	// 出力先にディレクトリがあると書き込みに失敗する
+	output := test.CreateDir(t, d, "output")
+	test.CreateDir(t, output, "2.txt")
+	test.CreateDir(t, output, "3.txt")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "x",
+		"-o", output,
+		"--keep-going",
+	})
+
+	stderr := new(bytes.Buffer)
+	rootCmd.SetErr(stderr)
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.EqualError(t, err, "2 of 4 files failed")
+
+	// 失敗したファイル以外は処理されていること
+	assert.Equal(t, "x", test.ReadString(t, filepath.Join(output, "1.txt")))
+	assert.Equal(t, "x", test.ReadString(t, filepath.Join(output, "4.txt")))
+
+	lines := strings.Split(strings.TrimSuffix(stderr.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.Regexp(t, `^FILE\s+ERROR$`, lines[0])
+	assert.Regexp(t, `^`+regexp.QuoteMeta(filepath.Join(input, "2.txt"))+`\s+open `+regexp.QuoteMeta(filepath.Join(output, "2.txt")), lines[1])
+	assert.Regexp(t, `^`+regexp.QuoteMeta(filepath.Join(input, "3.txt"))+`\s+open `+regexp.QuoteMeta(filepath.Join(output, "3.txt")), lines[2])
+}
+
+func TestReplaceCmd_KeepGoing_DirError(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateDir(t, d, "input")
+	test.CreateFileWriteString(t, input, "1.txt", "a")
+	inputSub := test.CreateDir(t, input, "sub")
+	test.CreateFileWriteString(t, inputSub, "2.txt", "a")
+	test.CreateFileWriteString(t, input, "3.txt", "a")
+
+	// 出力先のサブディレクトリと同じ名前のファイルがあると作成に失敗する
+	output := test.CreateDir(t, d, "output")
+	test.CreateFileWriteString(t, output, "sub", "")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "x",
+		"-o", output,
+		"--recursive",
+		"--keep-going",
+		"--jobs", "1",
+	})
+
+	stderr := new(bytes.Buffer)
+	rootCmd.SetErr(stderr)
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.EqualError(t, err, "1 of 3 files failed")
+
+	assert.Equal(t, "x", test.ReadString(t, filepath.Join(output, "1.txt")))
+	assert.Equal(t, "x", test.ReadString(t, filepath.Join(output, "3.txt")))
+	assert.Contains(t, stderr.String(), filepath.Join(input, "sub"))
+}
+
+func TestReplaceCmd_KeepGoing_NoError(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateDir(t, d, "input")
+	test.CreateFileWriteString(t, input, "1.txt", "a")
+
+	output := test.CreateDir(t, d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "x",
+		"-o", output,
+		"--keep-going",
+	})
+
+	stderr := new(bytes.Buffer)
+	rootCmd.SetErr(stderr)
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "", stderr.String())
+	assert.Equal(t, "x", test.ReadString(t, filepath.Join(output, "1.txt")))
+}
+
+func TestReplaceCmd_Rules_JSON(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc\nabc\naa")
+	output := filepath.Join(d, "output.txt")
+	rules := test.CreateFileWriteString(t, d, "rules.json", `[
+  {"type": "regex", "target": "a+", "replacement": "x"},
+  {"type": "string", "target": "\\n", "replacement": ",", "escape": true},
+  {"type": "string", "target": "xb", "replacement": "y"}
+]`)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"--rules", rules,
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	replaced := test.ReadString(t, output)
+	assert.Equal(t, "yc,yc,x", replaced)
+}
+
+func TestReplaceCmd_Rules_TSV(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc\nabc\naa")
+	output := filepath.Join(d, "output.txt")
+	rules := test.CreateFileWriteString(t, d, "rules.tsv", "regex\ta+\tx\nstring\t\\n\t,\ttrue\nstring\txb\ty\n")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"--rules", rules,
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	replaced := test.ReadString(t, output)
+	assert.Equal(t, "yc,yc,x", replaced)
+}
+
+func TestReplaceCmd_Rules_Escape_Quote(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{
+			name:     "rules.json",
+			contents: `[{"type": "string", "target": "\"", "replacement": "\\t'", "escape": true}]`,
+		},
+		{
+			name:     "rules.tsv",
+			contents: "string\t\"\t\\t'\ttrue\n",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input.txt", `say "hi"`)
+		output := filepath.Join(d, "output.txt")
+		// コマンドラインの--escapeと同じく、「"」はそのままの文字として扱う
+		rules := test.CreateFileWriteString(t, d, tt.name, tt.contents)
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs([]string{
+			"replace",
+			"-i", input,
+			"--rules", rules,
+			"-o", output,
+		})
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		require.NoError(t, err, tt.name)
+
+		replaced := test.ReadString(t, output)
+		assert.Equal(t, "say \t'hi\t'", replaced, tt.name)
+	}
+}
+
+func TestReplaceCmd_Rules_Invalid(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc")
+	output := filepath.Join(d, "output.txt")
+	rules := test.CreateFileWriteString(t, d, "rules.json", `[
+  {"type": "string", "target": "a", "replacement": "x"},
+  {"type": "regex", "target": "[a", "replacement": "x"}
+]`)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"--rules", rules,
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	assert.EqualError(t, err, "rules file "+rules+" is invalid: rule 2: regular expression is invalid: error parsing regexp: missing closing ]: `[a`")
+	assert.NoFileExists(t, output)
+}
+
+func TestReplaceCmd_Rules_WithRegex(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc")
+	output := filepath.Join(d, "output.txt")
+	rules := test.CreateFileWriteString(t, d, "rules.json", "[]")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"--rules", rules,
+		"-r", "a",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	assert.EqualError(t, err, "--rules cannot be specified with --regex, --string, --replacement or --escape")
+}
+
+func TestReplaceCmd_Rules_WithEscape(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc")
+	output := filepath.Join(d, "output.txt")
+	rules := test.CreateFileWriteString(t, d, "rules.json", "[]")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"--rules", rules,
+		"--escape",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	assert.EqualError(t, err, "--rules cannot be specified with --regex, --string, --replacement or --escape")
+}
+
+func TestReplaceCmd_NoneReplacement(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc")
+	output := filepath.Join(d, "output.txt")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	assert.EqualError(t, err, "--replacement must be specified")
+}
+
+func TestReplaceCmd_Stream_Regex(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc\nabc\naa")
+	output := filepath.Join(d, "output.txt")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-r", "a$",
+		"-t", "x",
+		"--stream",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	// 行ごとに置換されるため、$ は各行の末尾にマッチ
+	replaced := test.ReadString(t, output)
+	assert.Equal(t, "abc\nabc\nax", replaced)
+}
+
+func TestReplaceCmd_Stream_Large_SJIS(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	// 読み込み単位をまたぐように、改行を含まない大きな内容とする
+	contents := strings.Repeat("あいうえお", streamBufferSize/5)
+	input := test.CreateFileWriteBytes(t, d, "input.txt", test.StringToByte(t, contents, japanese.ShiftJIS))
+	output := filepath.Join(d, "output.txt")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-r", "えお(あ)",
+		"-t", "$1",
+		"--stream",
+		"--max-match-length", "3",
+		"--encoding", "sjis",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	replaced := test.ByteToString(t, test.ReadBytes(t, output), japanese.ShiftJIS)
+	assert.Equal(t, strings.Repeat("あいう", streamBufferSize/5-1)+"あいうえお", replaced)
+}
+
+func TestReplaceCmd_Stream_String_Binary(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteBytes(t, d, "input.txt", bytes.Repeat([]byte{0x00, 0x01, 0x02}, streamBufferSize))
+	output := filepath.Join(d, "output.txt")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "x01x02x00",
+		"-t", "x03",
+		"--stream",
+		"--encoding", "binary",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	replaced := test.ReadBytes(t, output)
+	assert.Equal(t, append(append([]byte{0x00}, bytes.Repeat([]byte{0x03}, streamBufferSize-1)...), 0x01, 0x02), replaced)
+}
+
+func TestReplaceCmd_Stream_Regex_Binary(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteBytes(t, d, "input.txt", bytes.Repeat([]byte{0x00, 0x01, 0x02}, streamBufferSize))
+	output := filepath.Join(d, "output.txt")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-r", "x01(x02)",
+		"-t", "$1",
+		"--stream",
+		"--max-match-length", "6",
+		"--encoding", "binary",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	replaced := test.ReadBytes(t, output)
+	assert.Equal(t, bytes.Repeat([]byte{0x00, 0x02}, streamBufferSize), replaced)
+}
+
+func TestReplaceCmd_Stream_Regex_Binary_NoMaxMatchLength(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteBytes(t, d, "input.txt", []byte{0x00, 0x01})
+	output := filepath.Join(d, "output.txt")
+	rules := test.CreateFileWriteString(t, d, "rules.json", `[{"type": "regex", "target": "x01", "replacement": ""}]`)
+
+	// 改行が無いので、行ごとの置換では全体を保持することになってしまう
+	for _, args := range [][]string{
+		{"-r", "x01", "-t", ""},
+		{"--rules", rules},
+	} {
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{
+			"replace",
+			"-i", input,
+			"--stream",
+			"--encoding", "binary",
+			"-o", output,
+		}, args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		assert.EqualError(t, err, "--max-match-length must be specified to use regex with --stream and --encoding binary", "args=%v", args)
+	}
+}
+
+func TestReplaceCmd_Stream_Rules(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc\nabc\naa")
+	output := filepath.Join(d, "output.txt")
+	rules := test.CreateFileWriteString(t, d, "rules.json", `[
+  {"type": "regex", "target": "a+", "replacement": "x"},
+  {"type": "string", "target": "\\n", "replacement": ",", "escape": true},
+  {"type": "string", "target": "xb", "replacement": "y"}
+]`)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"--rules", rules,
+		"--stream",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	replaced := test.ReadString(t, output)
+	assert.Equal(t, "yc,yc,x", replaced)
+}
+
+func TestReplaceCmd_Stream_TextAnchor(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc")
+	output := filepath.Join(d, "output.txt")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-r", "^a",
+		"-t", "x",
+		"--stream",
+		"--max-match-length", "10",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	assert.EqualError(t, err, "regular expression specified in --regex is invalid: \\A, \\z, or ^, $ without (?m) cannot be used with --max-match-length")
+}
+
+func TestReplaceCmd_MaxMatchLengthWithoutStream(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc")
+	output := filepath.Join(d, "output.txt")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-r", "a",
+		"-t", "x",
+		"--max-match-length", "10",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	assert.EqualError(t, err, "--max-match-length can only be specified with --stream")
+}
+
+func TestReplaceCmd_MaxMatchLength_Invalid(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc")
+	output := filepath.Join(d, "output.txt")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-r", "a",
+		"-t", "x",
+		"--stream",
+		"--max-match-length", "-1",
+		"-o", output,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	assert.EqualError(t, err, "max-match-length must be greater than or equal to 0")
+}
+
+func TestReplaceCmd_Report(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateDir(t, d, "input")
+	test.CreateFileWriteString(t, input, "1.txt", "abc\nあa")
+	test.CreateFileWriteString(t, input, "2.txt", "a")
+
+	// 出力先にディレクトリがあると書き込みに失敗する
+	output := test.CreateDir(t, d, "output")
+	test.CreateDir(t, output, "2.txt")
+
+	report := filepath.Join(d, "report.json")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "xx",
+		"-o", output,
+		"--keep-going",
+		"--report", report,
+	})
+	rootCmd.SetErr(new(bytes.Buffer))
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.EqualError(t, err, "1 of 2 files failed")
+
+	records := readReport(t, report)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, map[string]any{
+		"input_path":   filepath.Join(input, "1.txt"),
+		"output_path":  filepath.Join(output, "1.txt"),
+		"bytes_in":     float64(8),
+		"bytes_out":    float64(10),
+		"chars_in":     float64(6),
+		"chars_out":    float64(8),
+		"lines_in":     float64(2),
+		"lines_out":    float64(2),
+		"replacements": float64(2),
+		"error":        nil,
+	}, records[0])
+
+	assert.Equal(t, filepath.Join(input, "2.txt"), records[1]["input_path"])
+	assert.Equal(t, filepath.Join(output, "2.txt"), records[1]["output_path"])
+	assert.Nil(t, records[1]["bytes_in"])
+	assert.Nil(t, records[1]["replacements"])
+	assert.Regexp(t, "^open "+regexp.QuoteMeta(filepath.Join(output, "2.txt")), records[1]["error"])
+}
+
+func TestReplaceCmd_Report_JSONL_Binary(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteBytes(t, d, "input.txt", []byte{0x00, 0x01, 0x0A})
+	output := filepath.Join(d, "output.txt")
+	report := filepath.Join(d, "report.jsonl")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "x01",
+		"-t", "",
+		"--encoding", "binary",
+		"-o", output,
+		"--report", report,
+		"--report-format", "jsonl",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(test.ReadString(t, report), "\n"), "\n")
+	require.Len(t, lines, 1)
+
+	record := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.GreaterOrEqual(t, record["duration_ms"], float64(0))
+	delete(record, "duration_ms")
+
+	// binaryの場合は文字数と行数は数えない
+	assert.Equal(t, map[string]any{
+		"input_path":   input,
+		"output_path":  output,
+		"bytes_in":     float64(3),
+		"bytes_out":    float64(2),
+		"chars_in":     nil,
+		"chars_out":    nil,
+		"lines_in":     nil,
+		"lines_out":    nil,
+		"replacements": float64(1),
+		"error":        nil,
+	}, record)
+}
+
+func TestReplaceCmd_Report_Stdout(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	report := filepath.Join(d, "report.json")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", "-",
+		"-s", "a",
+		"-t", "b",
+		"-o", "-",
+		"--report", report,
+	})
+	rootCmd.SetIn(strings.NewReader("aaa\n"))
+	stdout := new(bytes.Buffer)
+	rootCmd.SetOut(stdout)
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "bbb\n", stdout.String())
+
+	records := readReport(t, report)
+	require.Len(t, records, 1)
+	assert.Equal(t, "-", records[0]["input_path"])
+	assert.Equal(t, "-", records[0]["output_path"])
+	assert.Equal(t, float64(1), records[0]["lines_in"])
+	assert.Equal(t, float64(3), records[0]["replacements"])
+}
+
+func TestReplaceCmd_ReportFormat_Invalid(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "a")
+	output := filepath.Join(d, "output.txt")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "b",
+		"-o", output,
+		"--report", filepath.Join(d, "report.csv"),
+		"--report-format", "csv",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	assert.EqualError(t, err, "report-format must be json or jsonl")
+}
+
+func TestReplaceCmd_ReportFormatWithoutReport(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "a")
+	output := filepath.Join(d, "output.txt")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"replace",
+		"-i", input,
+		"-s", "a",
+		"-t", "b",
+		"-o", output,
+		"--report-format", "jsonl",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	assert.EqualError(t, err, "--report-format can only be specified with --report")
+}
+
+// JSON形式のレポートを読み込みます(処理時間は値を確認したうえで除外)
+func readReport(t *testing.T, path string) []map[string]any {
+
+	records := []map[string]any{}
+	require.NoError(t, json.Unmarshal(test.ReadBytes(t, path), &records))
+
+	for _, record := range records {
+		assert.GreaterOrEqual(t, record["duration_ms"], float64(0))
+		delete(record, "duration_ms")
+	}
+
+	return records
+}
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	enc "github.com/onozaty/filep/encoding"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+const (
+	reportFormatJSON  = "json"
+	reportFormatJSONL = "jsonl"
+)
+
+// ファイルごとの処理結果を記録し、レポートとして出力するもの
+type reporter struct {
+	path   string
+	format string
+	// 文字数、行数を数えるためのエンコーディング(数えられない場合はnil)
+	encoding encoding.Encoding
+
+	mu      sync.Mutex
+	records []reportRecord
+}
+
+type reportRecord struct {
+	InputPath    string  `json:"input_path"`
+	OutputPath   string  `json:"output_path"`
+	BytesIn      *int64  `json:"bytes_in"`
+	BytesOut     *int64  `json:"bytes_out"`
+	CharsIn      *int64  `json:"chars_in"`
+	CharsOut     *int64  `json:"chars_out"`
+	LinesIn      *int64  `json:"lines_in"`
+	LinesOut     *int64  `json:"lines_out"`
+	Replacements *int    `json:"replacements"`
+	DurationMs   float64 `json:"duration_ms"`
+	Error        *string `json:"error"`
+}
+
+func newReporter(path string, format string, encodingName string) (*reporter, error) {
+
+	if format != reportFormatJSON && format != reportFormatJSONL {
+		return nil, fmt.Errorf("report-format must be %s or %s", reportFormatJSON, reportFormatJSONL)
+	}
+
+	// binaryなど文字として扱えないエンコーディングの場合は、文字数と行数は数えない
+	var encoding encoding.Encoding
+	if strings.ToLower(encodingName) != "binary" {
+		encoding, _ = enc.Encoding(encodingName)
+	}
+
+	return &reporter{
+		path:     path,
+		format:   format,
+		encoding: encoding,
+	}, nil
+}
+
+// 入出力の量を数えるように処理をラップします。
+func (r *reporter) countingProcess(process processFunc) processFunc {
+
+	return func(input io.Reader, output io.Writer) (processResult, error) {
+
+		inputCounter := newTextCounter(r.encoding)
+		outputCounter := newTextCounter(r.encoding)
+
+		countingInput := io.TeeReader(input, inputCounter)
+		result, err := process(countingInput, io.MultiWriter(output, outputCounter))
+		if err != nil {
+			return processResult{}, err
+		}
+
+		// 途中までしか読み込まない処理もあるので、入力の残りも数えておく
+		if _, err := io.Copy(io.Discard, countingInput); err != nil {
+			return processResult{}, err
+		}
+
+		result.inputStats = inputCounter.finish()
+		result.outputStats = outputCounter.finish()
+
+		return result, nil
+	}
+}
+
+func (r *reporter) record(result fileResult) {
+
+	if r == nil {
+		return
+	}
+
+	record := reportRecord{
+		InputPath:    result.inputPath,
+		OutputPath:   result.outputPath,
+		Replacements: result.replacements,
+		DurationMs:   float64(result.duration.Microseconds()) / 1000,
+	}
+
+	if result.err != nil {
+		message := result.err.Error()
+		record.Error = &message
+	} else {
+		if stats := result.inputStats; stats != nil {
+			record.BytesIn, record.CharsIn, record.LinesIn = stats.values()
+		}
+		if stats := result.outputStats; stats != nil {
+			record.BytesOut, record.CharsOut, record.LinesOut = stats.values()
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records = append(r.records, record)
+}
+
+func (r *reporter) write() error {
+
+	file, err := os.Create(r.path)
+	if err != nil {
+		return err
+	}
+
+	if err := r.encode(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func (r *reporter) encode(w io.Writer) error {
+
+	encoder := json.NewEncoder(w)
+
+	if r.format == reportFormatJSONL {
+		for _, record := range r.records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	encoder.SetIndent("", "  ")
+	// 対象が無い場合もnullではなく空の配列に
+	records := r.records
+	if records == nil {
+		records = []reportRecord{}
+	}
+	return encoder.Encode(records)
+}
+
+// 入出力の量
+type textStats struct {
+	bytes int64
+	// 文字数と行数(数えられない場合はnil)
+	chars *int64
+	lines *int64
+}
+
+func (s *textStats) values() (*int64, *int64, *int64) {
+
+	bytes := s.bytes
+	return &bytes, s.chars, s.lines
+}
+
+// 書き込まれたバイト数、文字数、行数を数えるもの
+type textCounter struct {
+	bytes   int64
+	decoder *transform.Writer
+	chars   *charCounter
+}
+
+func newTextCounter(encoding encoding.Encoding) *textCounter {
+
+	counter := &textCounter{}
+	if encoding != nil {
+		counter.chars = &charCounter{}
+		counter.decoder = transform.NewWriter(counter.chars, encoding.NewDecoder())
+	}
+
+	return counter
+}
+
+func (c *textCounter) Write(p []byte) (int, error) {
+
+	c.bytes += int64(len(p))
+
+	if c.decoder != nil {
+		if _, err := c.decoder.Write(p); err != nil {
+			// デコードできない場合は、文字数と行数は数えない
+			c.decoder = nil
+			c.chars = nil
+		}
+	}
+
+	return len(p), nil
+}
+
+func (c *textCounter) finish() *textStats {
+
+	stats := &textStats{
+		bytes: c.bytes,
+	}
+
+	if c.decoder != nil && c.decoder.Close() == nil {
+		chars := c.chars.chars
+		lines := c.chars.newlines
+		if chars != 0 && !c.chars.endsWithNewline {
+			// 改行で終わっていない最後の行も1行として数える
+			lines++
+		}
+		stats.chars = &chars
+		stats.lines = &lines
+	}
+
+	return stats
+}
+
+// デコードされた文字列(UTF-8)の文字数と改行数を数えるもの
+type charCounter struct {
+	chars           int64
+	newlines        int64
+	endsWithNewline bool
+}
+
+func (c *charCounter) Write(p []byte) (int, error) {
+
+	// デコード結果は文字単位で書き込まれるので、途中で区切られることは無い
+	c.chars += int64(utf8.RuneCount(p))
+	for _, b := range p {
+		if b == '\n' {
+			c.newlines++
+		}
+	}
+	if len(p) > 0 {
+		c.endsWithNewline = p[len(p)-1] == '\n'
+	}
+
+	return len(p), nil
+}
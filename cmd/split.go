@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/onozaty/filep/split/splitter"
+
+	"github.com/spf13/cobra"
+)
+
+func newSplitCmd() *cobra.Command {
+
+	splitCmd := &cobra.Command{
+		Use:   "split",
+		Short: "Split file contents into multiple files",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inputPath, _ := cmd.Flags().GetString("input")
+			outputPath, _ := cmd.Flags().GetString("output")
+
+			countingType, number, err := getFlagCountingTypeWithNumber(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			nameTemplate, _ := cmd.Flags().GetString("name")
+			charBoundary, _ := cmd.Flags().GetBool("char-boundary")
+			encoding, _ := cmd.Flags().GetString("encoding")
+
+			if number <= 0 {
+				return fmt.Errorf("number must be greater than 0")
+			}
+			if charBoundary && countingType != Bytes {
+				return fmt.Errorf("--char-boundary can only be specified with -b")
+			}
+			if outputPath == stdioPath {
+				return fmt.Errorf("standard output cannot be used with split")
+			}
+
+			namer, err := newChunkNamer(nameTemplate, inputPath)
+			if err != nil {
+				return err
+			}
+
+			// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
+			cmd.SilenceUsage = true
+
+			return runSplit(
+				inputPath,
+				outputPath,
+				splitCondition{
+					countingType: countingType,
+					number:       number,
+					charBoundary: charBoundary,
+				},
+				encoding,
+				namer,
+				cmd.InOrStdin())
+		},
+	}
+
+	splitCmd.Flags().StringP("input", "i", "", "Input file path.")
+	splitCmd.MarkFlagRequired("input")
+	splitCmd.Flags().StringP("output", "o", "", "Output dir path.")
+	splitCmd.MarkFlagRequired("output")
+
+	splitCmd.Flags().Int64P("byte", "b", 0, "Number of bytes per file.")
+	splitCmd.Flags().Int64P("char", "c", 0, "Number of characters per file.")
+	splitCmd.Flags().Int64P("line", "l", 0, "Number of lines per file.")
+
+	splitCmd.Flags().StringP("name", "", "{name}_{num:3}{ext}", "Name template of the split files. ({name}, {ext}, {num} or {num:DIGITS})")
+	splitCmd.Flags().BoolP("char-boundary", "", false, "Do not split in the middle of a character with -b.")
+	splitCmd.Flags().StringP("encoding", "", "UTF-8", "Encoding.")
+
+	return splitCmd
+}
+
+type splitCondition struct {
+	countingType CountingType
+	number       int64
+	charBoundary bool
+}
+
+func runSplit(inputPath string, outputDirPath string, condition splitCondition, encoding string, namer func(num int) string, stdin io.Reader) error {
+
+	splitter, err := newSplitter(condition, encoding)
+	if err != nil {
+		return err
+	}
+
+	input := stdin
+	if inputPath != stdioPath {
+		inputInfo, err := os.Stat(inputPath)
+		if err != nil {
+			return err
+		}
+		if inputInfo.IsDir() {
+			return fmt.Errorf("input must be a file: %s", inputPath)
+		}
+
+		inputFile, err := os.Open(inputPath)
+		if err != nil {
+			return err
+		}
+		defer inputFile.Close()
+
+		input = inputFile
+	}
+
+	if err := os.MkdirAll(outputDirPath, os.ModePerm); err != nil {
+		return err
+	}
+
+	created := []string{}
+	create := func(num int) (io.WriteCloser, error) {
+		path := filepath.Join(outputDirPath, namer(num))
+		file, err := os.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, path)
+		return file, nil
+	}
+
+	completed, err := splitter.Split(input, create)
+	if err != nil {
+		// 書き込み途中で終わったファイルは残さない
+		for _, path := range created[completed:] {
+			os.Remove(path)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func newSplitter(condition splitCondition, encoding string) (splitter.Splitter, error) {
+
+	switch condition.countingType {
+	case Bytes:
+		if condition.charBoundary {
+			return splitter.NewByteCharBoundarySplitter(condition.number, encoding)
+		}
+		return splitter.NewByteSplitter(condition.number)
+	case Chars:
+		return splitter.NewCharSplitter(condition.number, encoding)
+	case Lines:
+		return splitter.NewLineSplitter(condition.number, encoding)
+	default:
+		return nil, fmt.Errorf("invalid counting type: %d", condition.countingType)
+	}
+}
+
+var chunkNamePlaceholder = regexp.MustCompile(`\{(name|ext|num(?::(\d+))?)\}`)
+
+// 分割したファイルの名前を、テンプレートから作成する関数を返します。
+func newChunkNamer(template string, inputPath string) (func(num int) string, error) {
+
+	// 連番が無いと全て同じ名前になってしまう
+	hasNum := false
+	for _, submatches := range chunkNamePlaceholder.FindAllStringSubmatch(template, -1) {
+		if strings.HasPrefix(submatches[1], "num") {
+			hasNum = true
+		}
+	}
+	if !hasNum {
+		return nil, fmt.Errorf("--name must contain {num}")
+	}
+
+	base := filepath.Base(inputPath)
+	if inputPath == stdioPath {
+		base = "stdin"
+	}
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+
+	return func(num int) string {
+		return chunkNamePlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+			submatches := chunkNamePlaceholder.FindStringSubmatch(placeholder)
+			switch {
+			case submatches[1] == "name":
+				return name
+			case submatches[1] == "ext":
+				return ext
+			default:
+				// 桁数の指定があれば0埋め
+				digits, _ := strconv.Atoi(submatches[2])
+				return fmt.Sprintf("%0*d", digits, num)
+			}
+		})
+	}, nil
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestSplitCmd_Line(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "1\n2\n3\n4\n5\n")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", input,
+		"-o", output,
+		"-l", "2",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"input_001.txt", "input_002.txt", "input_003.txt"}, readDirNames(t, output))
+	assert.Equal(t, "1\n2\n", test.ReadString(t, filepath.Join(output, "input_001.txt")))
+	assert.Equal(t, "3\n4\n", test.ReadString(t, filepath.Join(output, "input_002.txt")))
+	assert.Equal(t, "5\n", test.ReadString(t, filepath.Join(output, "input_003.txt")))
+}
+
+func TestSplitCmd_Byte(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteBytes(t, d, "input.bin", []byte{0x01, 0x02, 0x03, 0x04, 0x05})
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", input,
+		"-o", output,
+		"-b", "3",
+		"--name", "{num}-{name}{ext}.part",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"1-input.bin.part", "2-input.bin.part"}, readDirNames(t, output))
+	assert.Equal(t, []byte{0x01, 0x02, 0x03}, test.ReadBytes(t, filepath.Join(output, "1-input.bin.part")))
+	assert.Equal(t, []byte{0x04, 0x05}, test.ReadBytes(t, filepath.Join(output, "2-input.bin.part")))
+}
+
+func TestSplitCmd_Byte_CharBoundary(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteBytes(t, d, "input.txt", test.StringToByte(t, "あいaうえ", japanese.ShiftJIS))
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", input,
+		"-o", output,
+		"-b", "3",
+		"--char-boundary",
+		"--encoding", "sjis",
+		"--name", "{num:2}{ext}",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"01.txt", "02.txt", "03.txt", "04.txt"}, readDirNames(t, output))
+	assert.Equal(t, "あ", test.ByteToString(t, test.ReadBytes(t, filepath.Join(output, "01.txt")), japanese.ShiftJIS))
+	assert.Equal(t, "いa", test.ByteToString(t, test.ReadBytes(t, filepath.Join(output, "02.txt")), japanese.ShiftJIS))
+	assert.Equal(t, "う", test.ByteToString(t, test.ReadBytes(t, filepath.Join(output, "03.txt")), japanese.ShiftJIS))
+	assert.Equal(t, "え", test.ByteToString(t, test.ReadBytes(t, filepath.Join(output, "04.txt")), japanese.ShiftJIS))
+}
+
+func TestSplitCmd_Char_Stdin(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", "-",
+		"-o", output,
+		"-c", "3",
+	})
+	rootCmd.SetIn(strings.NewReader("あいうえお"))
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"stdin_001", "stdin_002"}, readDirNames(t, output))
+	assert.Equal(t, "あいう", test.ReadString(t, filepath.Join(output, "stdin_001")))
+	assert.Equal(t, "えお", test.ReadString(t, filepath.Join(output, "stdin_002")))
+}
+
+func TestSplitCmd_ReadError(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", "-",
+		"-o", output,
+		"-l", "2",
+	})
+	rootCmd.SetIn(io.MultiReader(strings.NewReader("1\n2\n3\n"), iotest.ErrReader(errors.New("read error"))))
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	assert.EqualError(t, err, "read error")
+
+	// 書き込み途中だったファイルは削除される
+	assert.Equal(t, []string{"stdin_001"}, readDirNames(t, output))
+	assert.Equal(t, "1\n2\n", test.ReadString(t, filepath.Join(output, "stdin_001")))
+}
+
+func TestSplitCmd_EmptyInput(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", input,
+		"-o", output,
+		"-l", "2",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, readDirNames(t, output))
+}
+
+func TestSplitCmd_Error(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"-l", "1", "-c", "1"},
+			expected: "specify one of the following: -b, -c, -l",
+		},
+		{
+			args:     []string{"-l", "0"},
+			expected: "number must be greater than 0",
+		},
+		{
+			args:     []string{"-l", "1", "--char-boundary"},
+			expected: "--char-boundary can only be specified with -b",
+		},
+		{
+			args:     []string{"-l", "1", "--name", "{name}{ext}"},
+			expected: "--name must contain {num}",
+		},
+		{
+			args:     []string{"-c", "1", "--encoding", "utf"},
+			expected: "utf is invalid: htmlindex: invalid encoding name",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"split", "-i", input, "-o", output}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		assert.EqualError(t, err, tt.expected)
+	}
+}
+
+func TestSplitCmd_InputDir(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", d,
+		"-o", output,
+		"-l", "2",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	assert.EqualError(t, err, "input must be a file: "+d)
+}
+
+func readDirNames(t *testing.T, dir string) []string {
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
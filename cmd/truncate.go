@@ -2,10 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
 
+	"github.com/onozaty/filep/extract/extractor"
 	"github.com/onozaty/filep/truncate/truncator"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func newTruncateCmd() *cobra.Command {
@@ -23,12 +27,80 @@ func newTruncateCmd() *cobra.Command {
 				return err
 			}
 
-			recursive, _ := cmd.Flags().GetBool("recursive")
+			keep, err := getFlagKeep(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			// 先頭と末尾の大きさは、指定が無ければ単位と一緒に指定された数
+			headNum := getFlagInt64(cmd.Flags(), "head", number)
+			tailNum := getFlagInt64(cmd.Flags(), "tail", number)
+			marker, err := getFlagEscapedString(cmd.Flags(), "marker", true)
+			if err != nil {
+				return err
+			}
+			perLine, _ := cmd.Flags().GetBool("per-line")
+			suffix, err := getFlagEscapedString(cmd.Flags(), "suffix", true)
+			if err != nil {
+				return err
+			}
+			suffixInLimit, _ := cmd.Flags().GetBool("suffix-in-limit")
+			charBoundary, _ := cmd.Flags().GetBool("char-boundary")
+			wholeLines, _ := cmd.Flags().GetBool("whole-lines")
+			newline, err := getFlagNewline(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			charUnit, err := getFlagCharUnit(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			ambiguousWidth, _ := cmd.Flags().GetInt("ambiguous-width")
 			encoding, _ := cmd.Flags().GetString("encoding")
 
-			if number < 0 {
+			handleOptions, err := getHandleOptions(cmd)
+			if err != nil {
+				return err
+			}
+
+			if number < 0 || headNum < 0 || tailNum < 0 {
 				return fmt.Errorf("number must be greater than or equal to 0")
 			}
+			if (cmd.Flags().Changed("head") || cmd.Flags().Changed("tail")) && keep != truncator.KeepHeadTail {
+				return fmt.Errorf("--head and --tail can only be specified with --keep head-tail")
+			}
+			if cmd.Flags().Changed("marker") && keep != truncator.KeepHeadTail {
+				return fmt.Errorf("--marker can only be specified with --keep head-tail")
+			}
+			if charBoundary && countingType != Bytes {
+				return fmt.Errorf("--char-boundary can only be specified with -b")
+			}
+			if wholeLines && countingType != Bytes {
+				return fmt.Errorf("--whole-lines can only be specified with -b")
+			}
+			if wholeLines && (charBoundary || perLine) {
+				return fmt.Errorf("--whole-lines cannot be specified with --char-boundary or --per-line")
+			}
+			if perLine && countingType == Lines {
+				return fmt.Errorf("--per-line can only be specified with -b, -c or -w")
+			}
+			if perLine && keep != truncator.KeepHead {
+				return fmt.Errorf("--per-line can only be specified with --keep head")
+			}
+			if cmd.Flags().Changed("suffix") && keep != truncator.KeepHead {
+				return fmt.Errorf("--suffix can only be specified with --keep head")
+			}
+			if suffixInLimit && !cmd.Flags().Changed("suffix") {
+				return fmt.Errorf("--suffix-in-limit can only be specified with --suffix")
+			}
+			if cmd.Flags().Changed("newline") && countingType != Lines && !perLine && !wholeLines {
+				return fmt.Errorf("--newline can only be specified with -l, --per-line or --whole-lines")
+			}
+			if cmd.Flags().Changed("char-unit") && countingType != Chars {
+				return fmt.Errorf("--char-unit can only be specified with -c")
+			}
+			if cmd.Flags().Changed("ambiguous-width") && countingType != Width {
+				return fmt.Errorf("--ambiguous-width can only be specified with -w")
+			}
 
 			// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
 			cmd.SilenceUsage = true
@@ -37,24 +109,48 @@ func newTruncateCmd() *cobra.Command {
 				inputPath,
 				outputPath,
 				truncateCondition{
-					countingType: countingType,
-					number:       number,
+					countingType:   countingType,
+					number:         number,
+					keep:           keep,
+					headNum:        headNum,
+					tailNum:        tailNum,
+					marker:         marker,
+					perLine:        perLine,
+					suffix:         suffix,
+					suffixInLimit:  suffixInLimit,
+					charBoundary:   charBoundary,
+					wholeLines:     wholeLines,
+					newline:        newline,
+					charUnit:       charUnit,
+					ambiguousWidth: ambiguousWidth,
 				},
 				encoding,
-				recursive)
+				handleOptions)
 		},
 	}
 
 	truncateCmd.Flags().StringP("input", "i", "", "Input file/dir path.")
 	truncateCmd.MarkFlagRequired("input")
 	truncateCmd.Flags().StringP("output", "o", "", "Output file/dir path.")
-	truncateCmd.MarkFlagRequired("output")
 
 	truncateCmd.Flags().Int64P("byte", "b", 0, "Number of bytes.")
 	truncateCmd.Flags().Int64P("char", "c", 0, "Number of characters.")
 	truncateCmd.Flags().Int64P("line", "l", 0, "Number of lines.")
+	truncateCmd.Flags().Int64P("width", "w", 0, "Number of columns. (display width)")
+	truncateCmd.Flags().StringP("keep", "", "head", "Part to keep. (head, tail or head-tail)")
+	truncateCmd.Flags().Int64P("head", "", 0, "Size of the head to keep with --keep head-tail. (default is the specified number)")
+	truncateCmd.Flags().Int64P("tail", "", 0, "Size of the tail to keep with --keep head-tail. (default is the specified number)")
+	truncateCmd.Flags().StringP("marker", "", "...", "Marker between the head and the tail with --keep head-tail. (%d is replaced with the omitted size, escape sequences can be used)")
+	truncateCmd.Flags().BoolP("char-boundary", "", false, "Do not cut in the middle of a character with -b.")
+	truncateCmd.Flags().BoolP("whole-lines", "", false, "Keep only whole lines that fit within the number of bytes with -b.")
+	truncateCmd.Flags().StringP("char-unit", "", "rune", "Unit of characters for -c. (rune or grapheme)")
+	truncateCmd.Flags().IntP("ambiguous-width", "", 1, "Width of East Asian ambiguous characters for -w. (1 or 2)")
+	truncateCmd.Flags().BoolP("per-line", "", false, "Truncate each line instead of the whole file with -b, -c or -w.")
+	truncateCmd.Flags().StringP("suffix", "", "", "String to append only when the content was truncated. (escape sequences can be used)")
+	truncateCmd.Flags().BoolP("suffix-in-limit", "", false, "Count --suffix within the specified number.")
+	addNewlineFlag(truncateCmd.Flags(), "-l, --per-line and --whole-lines")
 
-	truncateCmd.Flags().BoolP("recursive", "", false, "Recursively traverse the input dir.")
+	addHandleFlags(truncateCmd.Flags())
 	truncateCmd.Flags().StringP("encoding", "", "UTF-8", "Encoding.")
 
 	return truncateCmd
@@ -63,32 +159,144 @@ func newTruncateCmd() *cobra.Command {
 type truncateCondition struct {
 	countingType CountingType
 	number       int64
+	// 先頭と末尾のどちらを残すか
+	keep truncator.Keep
+	// 先頭と末尾を残す場合の、それぞれの大きさと間に出力するマーカー
+	headNum int64
+	tailNum int64
+	marker  string
+	// 行ごとに切り捨てるか
+	perLine bool
+	// 切り捨てた場合に末尾に付ける文字列と、それを指定された数に含めるか
+	suffix        string
+	suffixInLimit bool
+	charBoundary  bool
+	// バイト単位の場合に、行の途中で切り捨てないか
+	wholeLines     bool
+	newline        extractor.Newline
+	charUnit       extractor.CharUnit
+	ambiguousWidth int
 }
 
-func runTruncate(inputPath string, outputPath string, condition truncateCondition, encoding string, recursive bool) error {
+func runTruncate(inputPath string, outputPath string, condition truncateCondition, encoding string, options handleOptions) error {
 
 	truncator, err := newTruncator(condition, encoding)
 	if err != nil {
 		return err
 	}
 
-	process := func(inputFilePath string, outputFilePath string) error {
-		return truncator.Truncate(inputFilePath, outputFilePath)
+	process := func(input io.Reader, output io.Writer) (processResult, error) {
+		return processResult{}, truncator.Truncate(input, output)
+	}
+	options.headSize = func(input *os.File) (int64, bool, error) {
+		return truncator.HeadSize(input)
 	}
 
-	return handle(inputPath, outputPath, process, recursive)
+	return handle(inputPath, outputPath, process, options)
 }
 
 func newTruncator(condition truncateCondition, encoding string) (*truncator.Truncator, error) {
 
+	if condition.perLine {
+		return newPerLineTruncator(condition, encoding)
+	}
+	if condition.keep == truncator.KeepHeadTail {
+		return newHeadTailTruncator(condition, encoding)
+	}
+	if condition.suffix != "" {
+		return newSuffixTruncator(condition, encoding)
+	}
+
 	switch condition.countingType {
 	case Bytes:
-		return truncator.NewByteTruncator(condition.number)
+		if condition.wholeLines {
+			return truncator.NewWholeLineByteTruncator(condition.number, condition.keep, condition.newline, encoding)
+		}
+		if condition.charBoundary {
+			return truncator.NewByteCharBoundaryTruncator(condition.number, condition.keep, encoding)
+		}
+		return truncator.NewByteTruncator(condition.number, condition.keep)
 	case Chars:
-		return truncator.NewCharTruncator(condition.number, encoding)
+		return truncator.NewCharTruncator(condition.number, condition.keep, condition.charUnit, encoding)
 	case Lines:
-		return truncator.NewLineTruncator(condition.number, encoding)
+		return truncator.NewLineTruncator(condition.number, condition.keep, condition.newline, encoding)
+	case Width:
+		return truncator.NewWidthTruncator(condition.number, condition.keep, condition.ambiguousWidth, encoding)
 	default:
 		return nil, fmt.Errorf("invalid counting type: %d", condition.countingType)
 	}
 }
+
+func newPerLineTruncator(condition truncateCondition, encoding string) (*truncator.Truncator, error) {
+
+	switch condition.countingType {
+	case Bytes:
+		// 行ごとの場合は、常に文字の途中で切り捨てない
+		return truncator.NewPerLineByteTruncator(condition.number, condition.suffix, condition.suffixInLimit, condition.newline, encoding)
+	case Chars:
+		return truncator.NewPerLineCharTruncator(condition.number, condition.charUnit, condition.suffix, condition.suffixInLimit, condition.newline, encoding)
+	case Width:
+		return truncator.NewPerLineWidthTruncator(condition.number, condition.ambiguousWidth, condition.suffix, condition.suffixInLimit, condition.newline, encoding)
+	default:
+		return nil, fmt.Errorf("invalid counting type: %d", condition.countingType)
+	}
+}
+
+func newHeadTailTruncator(condition truncateCondition, encoding string) (*truncator.Truncator, error) {
+
+	units, err := newSizedUnits(condition, encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	return truncator.NewHeadTailTruncator(condition.headNum, condition.tailNum, condition.marker, units)
+}
+
+func newSuffixTruncator(condition truncateCondition, encoding string) (*truncator.Truncator, error) {
+
+	units, err := newSizedUnits(condition, encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	return truncator.NewSuffixTruncator(condition.number, condition.suffix, condition.suffixInLimit, units)
+}
+
+// 数える単位に応じたSizedUnitsを作成します。
+func newSizedUnits(condition truncateCondition, encoding string) (extractor.SizedUnits, error) {
+
+	switch condition.countingType {
+	case Bytes:
+		if condition.wholeLines {
+			return extractor.NewWholeLineByteUnits(condition.newline, encoding)
+		}
+		if condition.charBoundary {
+			return extractor.NewByteCharBoundaryUnits(encoding)
+		}
+		return extractor.NewByteUnits(encoding)
+	case Chars:
+		return extractor.NewCharUnits(condition.charUnit, encoding)
+	case Lines:
+		return extractor.NewLineUnits(condition.newline, encoding)
+	case Width:
+		return extractor.NewWidthUnits(condition.ambiguousWidth, encoding)
+	default:
+		return extractor.SizedUnits{}, fmt.Errorf("invalid counting type: %d", condition.countingType)
+	}
+}
+
+func getFlagKeep(f *pflag.FlagSet) (truncator.Keep, error) {
+
+	keep, _ := f.GetString("keep")
+
+	switch keep {
+	case "head":
+		return truncator.KeepHead, nil
+	case "tail":
+		return truncator.KeepTail, nil
+	case "head-tail":
+		return truncator.KeepHeadTail, nil
+	default:
+		return 0, fmt.Errorf("keep must be head, tail or head-tail")
+	}
+}
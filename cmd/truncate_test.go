@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/onozaty/filep/test"
@@ -391,7 +393,7 @@ func TestTruncateCmd_NoNumberSpecified(t *testing.T) {
 	err := rootCmd.Execute()
 
 	// ASSERT
-	require.EqualError(t, err, "specify one of the following: -b, -c, -l")
+	require.EqualError(t, err, "specify one of the following: -b, -c, -l, -w")
 }
 
 func TestTruncateCmd_InvalidEncoding(t *testing.T) {
@@ -496,3 +498,1112 @@ func TestTruncateCmd_InvalidNumber(t *testing.T) {
 	// ASSERT
 	require.EqualError(t, err, "number must be greater than or equal to 0")
 }
+
+func TestTruncateCmd_InPlace_Dir(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateDir(t, d, "input")
+	test.CreateFileWriteString(t, input, "1.txt", "1\n2\n3\n")
+	test.CreateFileWriteString(t, input, "2.txt", "")
+
+	inputSub := test.CreateDir(t, input, "sub")
+	test.CreateFileWriteString(t, inputSub, "3.txt", "a\nb\n")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"truncate",
+		"-i", input,
+		"-l", "1",
+		"--in-place",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	assert.Equal(t, "1\n", test.ReadString(t, filepath.Join(input, "1.txt")))
+	assert.Equal(t, "", test.ReadString(t, filepath.Join(input, "2.txt")))
+	// 再帰指定無しなのでサブディレクトリはそのまま
+	assert.Equal(t, "a\nb\n", test.ReadString(t, filepath.Join(inputSub, "3.txt")))
+
+	entries, err := os.ReadDir(input)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
+
+func TestTruncateCmd_InPlace_File(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		contents string
+		expected string
+		// ファイル自体を切り詰めるか(書き直す場合は別のファイルに置き換わる)
+		sameFile bool
+	}{
+		{
+			args:     []string{"-b", "3"},
+			contents: "abcdef",
+			expected: "abc",
+			sameFile: true,
+		},
+		{
+			args:     []string{"-b", "10"},
+			contents: "abcdef",
+			expected: "abcdef",
+			sameFile: true,
+		},
+		{
+			args:     []string{"-l", "2"},
+			contents: "1\n2\n3\n",
+			expected: "1\n2\n",
+			sameFile: true,
+		},
+		{
+			args:     []string{"-c", "2"},
+			contents: "あいう",
+			expected: "あい",
+			sameFile: true,
+		},
+		{
+			args:     []string{"-b", "3", "--keep", "tail"},
+			contents: "abcdef",
+			expected: "def",
+			sameFile: false,
+		},
+		{
+			args:     []string{"-b", "3", "--suffix", "..."},
+			contents: "abcdef",
+			expected: "abc...",
+			sameFile: false,
+		},
+		{
+			// 不正なバイトは置き換えられるので書き直す
+			args:     []string{"-c", "3"},
+			contents: "a\xffbc",
+			expected: "a\uFFFDb",
+			sameFile: false,
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", tt.contents)
+		before, err := os.Stat(input)
+		require.NoError(t, err)
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"truncate", "-i", input, "--in-place"}, tt.args...))
+
+		// ACT
+		err = rootCmd.Execute()
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, test.ReadString(t, input), "args=%v", tt.args)
+
+		after, err := os.Stat(input)
+		require.NoError(t, err)
+		assert.Equal(t, tt.sameFile, os.SameFile(before, after), "args=%v", tt.args)
+
+		entries, err := os.ReadDir(d)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	}
+}
+
+func TestTruncateCmd_InPlace_File_Backup(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abcdef")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"truncate",
+		"-i", input,
+		"-b", "2",
+		"--in-place",
+		"--backup-suffix", ".bak",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	assert.Equal(t, "ab", test.ReadString(t, input))
+	// ファイル自体を切り詰めても、バックアップは元の内容のまま
+	assert.Equal(t, "abcdef", test.ReadString(t, input+".bak"))
+}
+
+func TestTruncateCmd_InPlace_File_Report(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abcdef")
+	report := filepath.Join(d, "report.json")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"truncate",
+		"-i", input,
+		"-b", "2",
+		"--in-place",
+		"--report", report,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+
+	assert.Equal(t, "ab", test.ReadString(t, input))
+	// レポートには書き直した場合と同じく入力と出力の量が含まれる
+	assert.Contains(t, test.ReadString(t, report), `"bytes_in": 6`)
+	assert.Contains(t, test.ReadString(t, report), `"bytes_out": 2`)
+}
+
+func TestTruncateCmd_Stdio(t *testing.T) {
+
+	// ARRANGE
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"truncate",
+		"-i", "-",
+		"-c", "2",
+		"-o", "-",
+	})
+
+	rootCmd.SetIn(strings.NewReader("あいう"))
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "あい", buf.String())
+}
+
+func TestTruncateCmd_DryRun(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateDir(t, d, "input")
+	test.CreateFileWriteString(t, input, "1.txt", "12345")
+	test.CreateFileWriteString(t, input, "2.txt", "12")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"truncate",
+		"-i", input,
+		"-b", "3",
+		"--in-place",
+		"--dry-run",
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"changed: "+filepath.Join(input, "1.txt")+" (5 -> 3 bytes)\n"+
+			"unchanged: "+filepath.Join(input, "2.txt")+" (2 -> 2 bytes)\n",
+		buf.String())
+
+	assert.Equal(t, "12345", test.ReadString(t, filepath.Join(input, "1.txt")))
+}
+
+func TestTruncateCmd_Report_Error(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input.txt", "abc")
+	report := filepath.Join(d, "report.json")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"truncate",
+		"-i", input,
+		// 入力と同じファイルを出力先に指定してエラーに
+		"-o", input,
+		"-b", "1",
+		"--report", report,
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.Error(t, err)
+
+	// 失敗した場合もレポートは出力されること
+	records := readReport(t, report)
+	require.Len(t, records, 1)
+	assert.Equal(t, input, records[0]["input_path"])
+	assert.Equal(t, err.Error(), records[0]["error"])
+}
+
+func TestTruncateCmd_File_Byte_CharBoundary(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteBytes(t, d, "input", test.StringToByte(t, "あいうえお", japanese.ShiftJIS))
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"truncate",
+		"-i", input,
+		"-o", output,
+		"-b", "7",
+		"--char-boundary",
+		"--encoding", "sjis",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "あいう", test.ByteToString(t, test.ReadBytes(t, output), japanese.ShiftJIS))
+}
+
+func TestTruncateCmd_CharBoundary_NotByte(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"truncate",
+		"-i", input,
+		"-o", output,
+		"-c", "7",
+		"--char-boundary",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	assert.EqualError(t, err, "--char-boundary can only be specified with -b")
+}
+
+func TestTruncateCmd_File_Line_Newline(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "1\r2\r3\r")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"truncate",
+		"-i", input,
+		"-o", output,
+		"-l", "2",
+		"--newline", "cr",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "1\r2\r", test.ReadString(t, output))
+}
+
+func TestTruncateCmd_Newline_NotLine(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"truncate",
+		"-i", input,
+		"-o", output,
+		"-c", "2",
+		"--newline", "cr",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	assert.EqualError(t, err, "--newline can only be specified with -l, --per-line or --whole-lines")
+}
+
+func TestTruncateCmd_File_Char_Grapheme(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "か\u3099き\u3099く")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"truncate",
+		"-i", input,
+		"-o", output,
+		"-c", "2",
+		"--char-unit", "grapheme",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "か\u3099き\u3099", test.ReadString(t, output))
+}
+
+func TestTruncateCmd_CharUnit_Error(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"-l", "2", "--char-unit", "grapheme"},
+			expected: "--char-unit can only be specified with -c",
+		},
+		{
+			args:     []string{"-c", "2", "--char-unit", "byte"},
+			expected: "char-unit must be rune or grapheme",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"truncate", "-i", input, "-o", output}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		assert.EqualError(t, err, tt.expected)
+	}
+}
+
+func TestTruncateCmd_File_Width(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "abあいう")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"truncate",
+		"-i", input,
+		"-o", output,
+		"-w", "5",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "abあ", test.ReadString(t, output))
+}
+
+func TestTruncateCmd_File_Width_LeadingZeroWidth(t *testing.T) {
+
+	// 先頭にある幅0の文字は、切り捨てられない限り残ること
+	tests := []struct {
+		contents string
+		args     []string
+		expected string
+	}{
+		{contents: "\nabc", args: []string{"-w", "2"}, expected: "\nab"},
+		{contents: "\nabc", args: []string{"-w", "2", "--suffix", "~"}, expected: "\nab~"},
+		{contents: "\nabc", args: []string{"-w", "3", "--keep", "tail"}, expected: "\nabc"},
+		{contents: "\ufeffabc", args: []string{"-w", "100"}, expected: "\ufeffabc"},
+		{contents: "\ufeffabc", args: []string{"-w", "100", "--suffix", "~"}, expected: "\ufeffabc"},
+		{contents: "\u0301abc", args: []string{"-w", "2"}, expected: "\u0301ab"},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", tt.contents)
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"truncate", "-i", input, "-o", output}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, test.ReadString(t, output), "contents=%q, args=%v", tt.contents, tt.args)
+	}
+}
+
+func TestTruncateCmd_File_Width_AmbiguousWidth(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "①②③")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"truncate",
+		"-i", input,
+		"-o", output,
+		"-w", "5",
+		"--ambiguous-width", "2",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "①②", test.ReadString(t, output))
+}
+
+func TestTruncateCmd_Width_Error(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"-c", "2", "--ambiguous-width", "2"},
+			expected: "--ambiguous-width can only be specified with -w",
+		},
+		{
+			args:     []string{"-w", "2", "--ambiguous-width", "0"},
+			expected: "ambiguous width must be 1 or 2",
+		},
+		{
+			args:     []string{"-w", "2", "-b", "2"},
+			expected: "specify one of the following: -b, -c, -l, -w",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"truncate", "-i", input, "-o", output}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		assert.EqualError(t, err, tt.expected)
+	}
+}
+
+func TestTruncateCmd_File_PerLine(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"-c", "3"},
+			expected: "abc\r\nあいう\n\n12",
+		},
+		{
+			args:     []string{"-c", "3", "--suffix", "…"},
+			expected: "abc…\r\nあいう…\n\n12",
+		},
+		{
+			args:     []string{"-b", "4", "--suffix", `\t`},
+			expected: "abcd\t\r\nあ\t\n\n12",
+		},
+		{
+			args:     []string{"-w", "5", "--suffix", "..."},
+			expected: "abcde...\r\nあい...\n\n12",
+		},
+		{
+			args:     []string{"-c", "3", "--newline", "crlf"},
+			expected: "abc\r\nあいう",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "abcdef\r\nあいうえ\n\n12")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"truncate", "-i", input, "-o", output, "--per-line"}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, test.ReadString(t, output), "args=%v", tt.args)
+	}
+}
+
+func TestTruncateCmd_PerLine_Error(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"-l", "2", "--per-line"},
+			expected: "--per-line can only be specified with -b, -c or -w",
+		},
+		{
+			args:     []string{"-c", "2", "--keep", "tail", "--suffix", "…"},
+			expected: "--suffix can only be specified with --keep head",
+		},
+		{
+			args:     []string{"-c", "2", "--per-line", "--suffix", `\x`},
+			expected: "could not parse value \\x of flag suffix: invalid syntax",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"truncate", "-i", input, "-o", output}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		assert.EqualError(t, err, tt.expected)
+	}
+}
+
+func TestTruncateCmd_File_KeepTail(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"-l", "2"},
+			expected: "ghi\nあいう\n",
+		},
+		{
+			args:     []string{"-b", "7"},
+			expected: "いう\n",
+		},
+		{
+			args:     []string{"-b", "8", "--char-boundary"},
+			expected: "いう\n",
+		},
+		{
+			args:     []string{"-c", "6"},
+			expected: "i\nあいう\n",
+		},
+		{
+			args:     []string{"-w", "4"},
+			expected: "いう\n",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "abc\ndef\nghi\nあいう\n")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"truncate", "-i", input, "-o", output, "--keep", "tail"}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, test.ReadString(t, output), "args=%v", tt.args)
+	}
+}
+
+func TestTruncateCmd_Stdin_KeepTail(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetIn(strings.NewReader("1\n2\n3\n4\n5"))
+	rootCmd.SetArgs([]string{
+		"truncate",
+		"-i", "-",
+		"-o", output,
+		"-l", "3",
+		"--keep", "tail",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "3\n4\n5", test.ReadString(t, output))
+}
+
+func TestTruncateCmd_Keep_Error(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"-l", "2", "--keep", "middle"},
+			expected: "keep must be head, tail or head-tail",
+		},
+		{
+			args:     []string{"-c", "2", "--keep", "tail", "--per-line"},
+			expected: "--per-line can only be specified with --keep head",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"truncate", "-i", input, "-o", output}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		assert.EqualError(t, err, tt.expected)
+	}
+}
+
+func TestTruncateCmd_File_KeepHeadTail(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"-l", "2"},
+			expected: "1\n2\n...\n6\n7\n",
+		},
+		{
+			args:     []string{"-l", "2", "--head", "1", "--marker", "... [%d lines omitted] ..."},
+			expected: "1\n... [4 lines omitted] ...\n6\n7\n",
+		},
+		{
+			args:     []string{"-l", "0", "--head", "3", "--tail", "1", "--marker", `[%d]`},
+			expected: "1\n2\n3\n[3]\n7\n",
+		},
+		{
+			args:     []string{"-b", "2", "--marker", `\t%d\t`},
+			expected: "1\n\t10\t7\n",
+		},
+		{
+			args:     []string{"-c", "4", "--head", "10"},
+			expected: "1\n2\n3\n4\n5\n6\n7\n",
+		},
+		{
+			// 「"」はそのままの文字として扱う
+			args:     []string{"-l", "1", "--marker", `"%d" lines`},
+			expected: "1\n\"5\" lines\n7\n",
+		},
+		{
+			args:     []string{"-l", "1", "--marker", `a\"b`},
+			expected: "1\na\"b\n7\n",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "1\n2\n3\n4\n5\n6\n7\n")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"truncate", "-i", input, "-o", output, "--keep", "head-tail"}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, test.ReadString(t, output), "args=%v", tt.args)
+	}
+}
+
+func TestTruncateCmd_File_KeepHeadTail_SJIS(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteBytes(t, d, "input", test.StringToByte(t, "あいうえお", japanese.ShiftJIS))
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"truncate",
+		"-i", input,
+		"-o", output,
+		"-c", "1",
+		"--keep", "head-tail",
+		"--marker", "（%d文字省略）",
+		"--encoding", "sjis",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "あ（3文字省略）お", test.ByteToString(t, test.ReadBytes(t, output), japanese.ShiftJIS))
+}
+
+func TestTruncateCmd_KeepHeadTail_Error(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"-l", "2", "--head", "1"},
+			expected: "--head and --tail can only be specified with --keep head-tail",
+		},
+		{
+			args:     []string{"-l", "2", "--keep", "tail", "--tail", "1"},
+			expected: "--head and --tail can only be specified with --keep head-tail",
+		},
+		{
+			args:     []string{"-l", "2", "--marker", "..."},
+			expected: "--marker can only be specified with --keep head-tail",
+		},
+		{
+			args:     []string{"-l", "2", "--keep", "head-tail", "--tail", "-1"},
+			expected: "number must be greater than or equal to 0",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"truncate", "-i", input, "-o", output}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		assert.EqualError(t, err, tt.expected)
+	}
+}
+
+func TestTruncateCmd_File_Suffix(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"-c", "3", "--suffix", "…"},
+			expected: "abc…",
+		},
+		{
+			args:     []string{"-c", "3", "--suffix", "…", "--suffix-in-limit"},
+			expected: "ab…",
+		},
+		{
+			args:     []string{"-c", "20", "--suffix", "…"},
+			expected: "abcdef\nあいう\n",
+		},
+		{
+			args:     []string{"-l", "1", "--suffix", `[truncated]\n`},
+			expected: "abcdef\n[truncated]\n",
+		},
+		{
+			args:     []string{"-b", "13", "--suffix", "...", "--suffix-in-limit"},
+			expected: "abcdef\nあ...",
+		},
+		{
+			args:     []string{"-b", "12", "--suffix", "...", "--suffix-in-limit", "--char-boundary"},
+			expected: "abcdef\n...",
+		},
+		{
+			args:     []string{"-w", "9", "--suffix", "~", "--suffix-in-limit"},
+			expected: "abcdef\nあ~",
+		},
+		{
+			// 「"」はそのままの文字として扱う
+			args:     []string{"-c", "3", "--suffix", `"`},
+			expected: "abc\"",
+		},
+		{
+			args:     []string{"-c", "3", "--suffix", `"(\"more\")"\t`},
+			expected: "abc\"(\"more\")\"\t",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "abcdef\nあいう\n")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"truncate", "-i", input, "-o", output}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, test.ReadString(t, output), "args=%v", tt.args)
+	}
+}
+
+func TestTruncateCmd_File_PerLine_SuffixInLimit(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "abcdef\nabc\n")
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"truncate",
+		"-i", input,
+		"-o", output,
+		"-c", "4",
+		"--per-line",
+		"--suffix", "..",
+		"--suffix-in-limit",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "ab..\nabc\n", test.ReadString(t, output))
+}
+
+func TestTruncateCmd_Suffix_Error(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"-c", "2", "--suffix-in-limit"},
+			expected: "--suffix-in-limit can only be specified with --suffix",
+		},
+		{
+			args:     []string{"-c", "2", "--keep", "head-tail", "--suffix", "…"},
+			expected: "--suffix can only be specified with --keep head",
+		},
+		{
+			args:     []string{"-c", "2", "--suffix", "...", "--suffix-in-limit"},
+			expected: "suffix must not exceed the number",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"truncate", "-i", input, "-o", output}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		assert.EqualError(t, err, tt.expected)
+	}
+}
+
+func TestTruncateCmd_File_WholeLines(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"-b", "9"},
+			expected: "abc\n",
+		},
+		{
+			args:     []string{"-b", "10"},
+			expected: "abc\n",
+		},
+		{
+			args:     []string{"-b", "11"},
+			expected: "abc\nあい\n",
+		},
+		{
+			args:     []string{"-b", "2"},
+			expected: "",
+		},
+		{
+			args:     []string{"-b", "8", "--keep", "tail"},
+			expected: "xyz\n",
+		},
+		{
+			args:     []string{"-b", "11", "--suffix", "(more)"},
+			expected: "abc\nあい\n(more)",
+		},
+		{
+			args:     []string{"-b", "4", "--keep", "head-tail"},
+			expected: "abc\n...\nxyz\n",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "abc\nあい\nxyz\n")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"truncate", "-i", input, "-o", output, "--whole-lines"}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, test.ReadString(t, output), "args=%v", tt.args)
+	}
+}
+
+func TestTruncateCmd_File_WholeLines_Encoding(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteBytes(t, d, "input", test.StringToByte(t, "あいう\r\nえお\r\n", japanese.ShiftJIS))
+	output := filepath.Join(d, "output")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"truncate",
+		"-i", input,
+		"-o", output,
+		"-b", "10",
+		"--whole-lines",
+		"--newline", "crlf",
+		"--encoding", "sjis",
+	})
+
+	// ACT
+	err := rootCmd.Execute()
+
+	// ASSERT
+	require.NoError(t, err)
+	// Shift_JISで数えるので、UTF-8では収まらない1行目も含まれる
+	assert.Equal(t, "あいう\r\n", test.ByteToString(t, test.ReadBytes(t, output), japanese.ShiftJIS))
+}
+
+func TestTruncateCmd_WholeLines_Error(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"-c", "2", "--whole-lines"},
+			expected: "--whole-lines can only be specified with -b",
+		},
+		{
+			args:     []string{"-b", "2", "--whole-lines", "--char-boundary"},
+			expected: "--whole-lines cannot be specified with --char-boundary or --per-line",
+		},
+		{
+			args:     []string{"-b", "2", "--whole-lines", "--per-line"},
+			expected: "--whole-lines cannot be specified with --char-boundary or --per-line",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		d := t.TempDir()
+
+		input := test.CreateFileWriteString(t, d, "input", "")
+		output := filepath.Join(d, "output")
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"truncate", "-i", input, "-o", output}, tt.args...))
+
+		// ACT
+		err := rootCmd.Execute()
+
+		// ASSERT
+		assert.EqualError(t, err, tt.expected)
+	}
+}
@@ -0,0 +1,99 @@
+package encoding
+
+import (
+	"io"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+const charReaderBufferSize = 4096
+
+// 入力をデコードしながら、1文字ずつ元のバイト列と合わせて読み込むためのものです。
+// 文字の途中で区切らないように、バイト数で扱う場合に利用します。
+type CharReader struct {
+	reader  io.Reader
+	decoder transform.Transformer
+	buf     []byte
+	start   int
+	end     int
+	eof     bool
+	dst     [64]byte
+}
+
+func NewCharReader(reader io.Reader, encoding encoding.Encoding) *CharReader {
+
+	return &CharReader{
+		reader:  reader,
+		decoder: encoding.NewDecoder(),
+		buf:     make([]byte, charReaderBufferSize),
+	}
+}
+
+// 1文字を読み込み、デコードした文字と元のバイト列を返します。
+// エスケープシーケンスのように文字として出力されないバイト列は、次の文字のバイト列に含めます。
+// 返したバイト列は次に読み込むまでの間のみ有効です。
+func (r *CharReader) ReadChar() (string, []byte, error) {
+
+	// 1文字分としてデコードできるまで、対象のバイト数を増やしていく
+	consumed := 0
+	n := 1
+	for {
+		if r.start+n > r.end {
+			if !r.eof {
+				if err := r.fill(); err != nil {
+					return "", nil, err
+				}
+				continue
+			}
+			if r.start == r.end {
+				return "", nil, io.EOF
+			}
+			n = r.end - r.start
+		}
+
+		atEOF := r.eof && r.start+n == r.end
+		nDst, nSrc, err := r.decoder.Transform(r.dst[:], r.buf[r.start+consumed:r.start+n], atEOF)
+		consumed += nSrc
+
+		if nDst > 0 {
+			raw := r.buf[r.start : r.start+consumed]
+			r.start += consumed
+			return string(r.dst[:nDst]), raw, nil
+		}
+
+		if err != nil && err != transform.ErrShortSrc {
+			return "", nil, err
+		}
+
+		if atEOF {
+			// 文字にならずに終端に達した場合、残りはバイト列としてのみ返す
+			raw := r.buf[r.start:r.end]
+			r.start = r.end
+			return "", raw, nil
+		}
+
+		n = max(n+1, consumed+1)
+	}
+}
+
+func (r *CharReader) fill() error {
+
+	// 未読の部分を先頭に寄せてから読み込む
+	copy(r.buf, r.buf[r.start:r.end])
+	r.end -= r.start
+	r.start = 0
+
+	if r.end == len(r.buf) {
+		r.buf = append(r.buf, make([]byte, len(r.buf))...)
+	}
+
+	n, err := r.reader.Read(r.buf[r.end:])
+	r.end += n
+	if err == io.EOF {
+		r.eof = true
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,118 @@
+package encoding
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestCharReader(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		encoding encoding.Encoding
+	}{
+		{name: "UTF-8", encoding: unicode.UTF8},
+		{name: "Shift_JIS", encoding: japanese.ShiftJIS},
+		{name: "EUC-JP", encoding: japanese.EUCJP},
+		{name: "ISO-2022-JP", encoding: japanese.ISO2022JP},
+		{name: "UTF-16LE", encoding: unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// ARRANGE
+			input := test.StringToByte(t, "aあ\n漢い1", tt.encoding)
+
+			// 1バイトずつしか読み込めない場合も同じ結果になること
+			reader := NewCharReader(iotest.OneByteReader(bytes.NewReader(input)), tt.encoding)
+
+			// ACT
+			chars, raw := readAllChars(t, reader)
+
+			// ASSERT
+			assert.Equal(t, []string{"a", "あ", "\n", "漢", "い", "1"}, chars)
+			assert.Equal(t, input, raw)
+		})
+	}
+}
+
+func TestCharReader_ISO2022JP(t *testing.T) {
+
+	// ARRANGE
+	input := test.StringToByte(t, "aあい", japanese.ISO2022JP)
+	reader := NewCharReader(bytes.NewReader(input), japanese.ISO2022JP)
+
+	// ACT
+	var raws [][]byte
+	for {
+		_, raw, err := reader.ReadChar()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		raws = append(raws, bytes.Clone(raw))
+	}
+
+	// ASSERT
+	// エスケープシーケンスは次の文字に含まれ、末尾のエスケープシーケンスは文字無しで返る
+	assert.Equal(t, [][]byte{
+		[]byte("a"),
+		[]byte("\x1b$B$\""),
+		[]byte("$$"),
+		[]byte("\x1b(B"),
+	}, raws)
+}
+
+func TestCharReader_Invalid(t *testing.T) {
+
+	// ARRANGE
+	input := []byte("a\xffb\xe3\x81")
+	reader := NewCharReader(bytes.NewReader(input), unicode.UTF8)
+
+	// ACT
+	chars, raw := readAllChars(t, reader)
+
+	// ASSERT
+	assert.Equal(t, "a�b�", strings.Join(chars, ""))
+	assert.Equal(t, input, raw)
+}
+
+func TestCharReader_Empty(t *testing.T) {
+
+	// ARRANGE
+	reader := NewCharReader(bytes.NewReader([]byte{}), unicode.UTF8)
+
+	// ACT
+	_, _, err := reader.ReadChar()
+
+	// ASSERT
+	assert.Equal(t, io.EOF, err)
+}
+
+func readAllChars(t *testing.T, reader *CharReader) ([]string, []byte) {
+
+	chars := []string{}
+	raw := []byte{}
+	for {
+		c, b, err := reader.ReadChar()
+		if err == io.EOF {
+			return chars, raw
+		}
+		require.NoError(t, err)
+		if c != "" {
+			chars = append(chars, c)
+		}
+		raw = append(raw, b...)
+	}
+}
@@ -0,0 +1,36 @@
+package escape
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Unescape は \n のように指定されたエスケープシーケンスを、対応する文字に変換します。
+// (エスケープされていない「"」と改行は、そのままの文字として扱います)
+func Unescape(str string) (string, error) {
+
+	return strconv.Unquote(`"` + quoteLiterals(str) + `"`)
+}
+
+// ダブルクォートで囲んでアンクォートする際に、そのままでは扱えない文字をエスケープします。
+func quoteLiterals(str string) string {
+
+	var builder strings.Builder
+	escaped := false
+	for _, c := range str {
+		if !escaped {
+			switch c {
+			case '"':
+				builder.WriteString(`\"`)
+				continue
+			case '\n':
+				builder.WriteString(`\n`)
+				continue
+			}
+		}
+		escaped = c == '\\' && !escaped
+		builder.WriteRune(c)
+	}
+
+	return builder.String()
+}
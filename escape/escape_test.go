@@ -0,0 +1,44 @@
+package escape
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnescape(t *testing.T) {
+
+	tests := []struct {
+		str      string
+		expected string
+	}{
+		{str: `a\tb\n`, expected: "a\tb\n"},
+		{str: `あ\x41`, expected: "あA"},
+		{str: `"`, expected: `"`},
+		{str: `a"b\"c`, expected: `a"b"c`},
+		{str: `\\"`, expected: `\"`},
+		{str: "a\nb", expected: "a\nb"},
+		{str: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		// ACT
+		result, err := Unescape(tt.str)
+
+		// ASSERT
+		require.NoError(t, err, "str=%s", tt.str)
+		assert.Equal(t, tt.expected, result, "str=%s", tt.str)
+	}
+}
+
+func TestUnescape_Invalid(t *testing.T) {
+
+	for _, str := range []string{`\q`, `a\`} {
+		// ACT
+		_, err := Unescape(str)
+
+		// ASSERT
+		assert.Error(t, err, "str=%s", str)
+	}
+}
@@ -1,48 +1,180 @@
 package extractor
 
 import (
-	"fmt"
+	"bufio"
+	"bytes"
 	"io"
-	"os"
+
+	enc "github.com/onozaty/filep/encoding"
+
+	"golang.org/x/text/encoding"
 )
 
 type byteExtractor struct {
-	start int64
-	end   int64
+	ranges []Range
+	// 文字の途中で区切らない場合のエンコーディング
+	charBoundary encoding.Encoding
 }
 
 func NewByteExtractor(start int64, end int64) (Extractor, error) {
 
-	if start < 1 || end < start {
-		return nil, fmt.Errorf("invalid range: start = %d, end = %d", start, end)
+	if err := validateRange(start, end); err != nil {
+		return nil, err
 	}
 
 	return &byteExtractor{
-		start: start,
-		end:   end,
+		ranges: []Range{{Start: start, End: end}},
 	}, nil
 }
 
-func (t *byteExtractor) Extract(inputFilePath string, outputFilePath string) error {
+func NewByteRangesExtractor(ranges []Range) (Extractor, error) {
+
+	if err := validateRanges(ranges); err != nil {
+		return nil, err
+	}
+
+	return &byteExtractor{
+		ranges: ranges,
+	}, nil
+}
 
-	input, err := os.Open(inputFilePath)
+// 範囲内に収まる文字だけを取り出すことで、文字の途中で区切らないようにします。
+func NewByteCharBoundaryExtractor(start int64, end int64, encodingName string) (Extractor, error) {
+
+	if err := validateRange(start, end); err != nil {
+		return nil, err
+	}
+
+	encoding, err := enc.Encoding(encodingName)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	return &byteExtractor{
+		ranges:       []Range{{Start: start, End: end}},
+		charBoundary: encoding,
+	}, nil
+}
+
+func NewByteCharBoundaryRangesExtractor(ranges []Range, encodingName string) (Extractor, error) {
+
+	if err := validateRanges(ranges); err != nil {
+		return nil, err
 	}
-	defer input.Close()
 
-	out, err := os.Create(outputFilePath)
+	encoding, err := enc.Encoding(encodingName)
 	if err != nil {
+		return nil, err
+	}
+
+	return &byteExtractor{
+		ranges:       ranges,
+		charBoundary: encoding,
+	}, nil
+}
+
+func (t *byteExtractor) Extract(input io.Reader, output io.Writer) error {
+
+	if t.charBoundary != nil {
+		return t.extractCharBoundary(input, output)
+	}
+
+	if len(t.ranges) == 1 && isFromEnd(t.ranges[0].Start, t.ranges[0].End) {
+		return t.extractFromEnd(input, output, t.ranges[0])
+	}
+
+	position := int64(1)
+	for _, r := range t.ranges {
+		// 開始位置を変更
+		if err := skipBytes(input, r.Start-position); err != nil {
+			return err
+		}
+
+		_, err := io.CopyN(output, input, r.End-r.Start+1)
+		if err == io.EOF { // 入力ファイルが指定サイズ未満の場合はEOFが返される(そこまでの書き込みでOK)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		position = r.End + 1
+	}
+
+	return nil
+}
+
+func (t *byteExtractor) extractFromEnd(input io.Reader, output io.Writer, r Range) error {
+
+	if seeker, base, size, ok := seekableSize(input); ok {
+		// サイズが分かれば、先頭からの位置に変換してシークで取り出せる
+		start := max(resolvePosition(r.Start, size), 1)
+		end := resolvePosition(r.End, size)
+		if end < start {
+			return nil
+		}
+
+		if _, err := seeker.Seek(base+start-1, io.SeekStart); err != nil {
+			return err
+		}
+
+		_, err := io.CopyN(output, seeker, end-start+1)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		return nil
+	}
+
+	reader := bufio.NewReader(input)
+	writer := bufio.NewWriter(output)
+
+	if err := extractUnits(reader.ReadByte, writer.WriteByte, r.Start, r.End); err != nil {
 		return err
 	}
-	defer out.Close()
 
-	// 開始位置を変更
-	input.Seek(t.start-1, 0)
-	_, err = io.CopyN(out, input, t.end-t.start+1)
-	if err != nil && err != io.EOF { // 入力ファイルが指定サイズ未満の場合はEOFが返される(そこまでの書き込みでOK)
+	return writer.Flush()
+}
+
+func skipBytes(input io.Reader, n int64) error {
+
+	// シーク可能ならシークで(パイプなどはシークに失敗するので読み捨てる)
+	if seeker, ok := input.(io.Seeker); ok {
+		if _, err := seeker.Seek(n, io.SeekCurrent); err == nil {
+			return nil
+		}
+	}
+
+	_, err := io.CopyN(io.Discard, input, n)
+	if err != nil && err != io.EOF {
 		return err
 	}
 
 	return nil
 }
+
+func (t *byteExtractor) extractCharBoundary(input io.Reader, output io.Writer) error {
+
+	reader := enc.NewCharReader(input, t.charBoundary)
+	writer := bufio.NewWriter(output)
+
+	// 文字ごとに、元のバイト列とそのバイト数を大きさとして扱う
+	next := func() ([]byte, int64, error) {
+		_, raw, err := reader.ReadChar()
+		if err != nil {
+			return nil, 0, err
+		}
+		// 末尾からの位置の場合は保持しておくことがあるのでコピー
+		return bytes.Clone(raw), int64(len(raw)), nil
+	}
+
+	write := func(raw []byte) error {
+		_, err := writer.Write(raw)
+		return err
+	}
+
+	if err := extractSizedUnits(next, write, t.ranges); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
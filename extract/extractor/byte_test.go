@@ -1,13 +1,17 @@
 package extractor
 
 import (
-	"os"
+	"bytes"
+	"io"
+	"math"
 	"path/filepath"
 	"testing"
 
 	"github.com/onozaty/filep/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
 )
 
 func TestNewByteExtractor(t *testing.T) {
@@ -24,7 +28,7 @@ func TestNewByteExtractor(t *testing.T) {
 		// ACT
 		extractor, err := NewByteExtractor(1, 10)
 		require.NoError(t, err)
-		err = extractor.Extract(input, output)
+		err = extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -39,7 +43,7 @@ func TestNewByteExtractor(t *testing.T) {
 		// ACT
 		extractor, err := NewByteExtractor(2, 9)
 		require.NoError(t, err)
-		err = extractor.Extract(input, output)
+		err = extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -54,7 +58,7 @@ func TestNewByteExtractor(t *testing.T) {
 		// ACT
 		extractor, err := NewByteExtractor(1, 11)
 		require.NoError(t, err)
-		err = extractor.Extract(input, output)
+		err = extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -69,7 +73,7 @@ func TestNewByteExtractor(t *testing.T) {
 		// ACT
 		extractor, err := NewByteExtractor(12, 13)
 		require.NoError(t, err)
-		err = extractor.Extract(input, output)
+		err = extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -80,60 +84,246 @@ func TestNewByteExtractor(t *testing.T) {
 	}
 }
 
-func TestNewByteExtractor_InputFileNotFound(t *testing.T) {
+func TestNewByteExtractor_InvalidRange_Start(t *testing.T) {
 
-	// ARRANGE
-	d := t.TempDir()
+	// ACT
+	_, err := NewByteExtractor(0, 10)
+
+	// ASSERT
+	assert.EqualError(t, err, "invalid range: start = 0, end = 10")
+}
+
+func TestNewByteExtractor_InvalidRange_End(t *testing.T) {
+
+	// ACT
+	_, err := NewByteExtractor(10, 9)
+
+	// ASSERT
+	assert.EqualError(t, err, "invalid range: start = 10, end = 9")
+}
+
+func TestNewByteExtractor_NotSeekable(t *testing.T) {
 
-	input := filepath.Join(d, "xxxx")
-	output := filepath.Join(d, "output")
+	// ARRANGE
+	input := io.MultiReader(bytes.NewReader([]byte{0x01, 0x02, 0x03, 0x04, 0x05}))
+	output := new(bytes.Buffer)
 
 	// ACT
-	extractor, err := NewByteExtractor(1, 10)
+	extractor, err := NewByteExtractor(2, 3)
 	require.NoError(t, err)
 	err = extractor.Extract(input, output)
 
 	// ASSERT
-	require.Error(t, err)
-	pathErr := err.(*os.PathError)
-	assert.Equal(t, input, pathErr.Path)
-	assert.Equal(t, "open", pathErr.Op)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x02, 0x03}, output.Bytes())
+}
+
+func TestNewByteExtractor_FromEnd(t *testing.T) {
+
+	contents := []byte{0x01, 0x02, 0x03, 0x04, 0x05}
+
+	tests := []struct {
+		start    int64
+		end      int64
+		expected []byte
+	}{
+		{start: -2, end: math.MaxInt64, expected: []byte{0x04, 0x05}},
+		{start: -1, end: -1, expected: []byte{0x05}},
+		{start: 2, end: -2, expected: []byte{0x02, 0x03, 0x04}},
+		{start: -10, end: -5, expected: []byte{0x01}},
+		{start: -10, end: 2, expected: []byte{0x01, 0x02}},
+		{start: 4, end: -3, expected: []byte{}},
+		{start: -6, end: -6, expected: []byte{}},
+	}
+
+	for _, tt := range tests {
+		extractor, err := NewByteExtractor(tt.start, tt.end)
+		require.NoError(t, err)
+
+		// シーク可能な場合
+		{
+			output := new(bytes.Buffer)
+			err := extractor.Extract(bytes.NewReader(contents), output)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, append([]byte{}, output.Bytes()...), "start=%d, end=%d", tt.start, tt.end)
+		}
+		// シークできない場合
+		{
+			output := new(bytes.Buffer)
+			err := extractor.Extract(io.MultiReader(bytes.NewReader(contents)), output)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, append([]byte{}, output.Bytes()...), "start=%d, end=%d", tt.start, tt.end)
+		}
+	}
+}
+
+func TestNewByteExtractor_InvalidRange_FromEnd(t *testing.T) {
+
+	// ACT
+	_, err := NewByteExtractor(-9, -10)
+
+	// ASSERT
+	assert.EqualError(t, err, "invalid range: start = -9, end = -10")
 }
 
-func TestNewByteExtractor_OutputFileNotFound(t *testing.T) {
+func TestNewByteRangesExtractor(t *testing.T) {
 
 	// ARRANGE
-	d := t.TempDir()
+	contents := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07}
+
+	extractor, err := NewByteRangesExtractor([]Range{{Start: 1, End: 2}, {Start: 4, End: 4}, {Start: 6, End: math.MaxInt64}})
+	require.NoError(t, err)
+
+	// シーク可能な場合
+	{
+		output := new(bytes.Buffer)
+
+		// ACT
+		err := extractor.Extract(bytes.NewReader(contents), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0x01, 0x02, 0x04, 0x06, 0x07}, output.Bytes())
+	}
+	// シークできない場合
+	{
+		output := new(bytes.Buffer)
 
-	input := test.CreateFileWriteBytes(t, d, "input", []byte{})
-	output := filepath.Join(d, "non", "output")
+		// ACT
+		err := extractor.Extract(io.MultiReader(bytes.NewReader(contents)), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0x01, 0x02, 0x04, 0x06, 0x07}, output.Bytes())
+	}
+}
+
+func TestNewByteRangesExtractor_Short(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewByteRangesExtractor([]Range{{Start: 2, End: 3}, {Start: 5, End: 10}, {Start: 20, End: 30}})
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
 
 	// ACT
-	extractor, err := NewByteExtractor(1, 10)
+	err = extractor.Extract(bytes.NewReader([]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}), output)
+
+	// ASSERT
 	require.NoError(t, err)
-	err = extractor.Extract(input, output)
+	assert.Equal(t, []byte{0x02, 0x03, 0x05, 0x06}, output.Bytes())
+}
+
+func TestNewByteRangesExtractor_Overlap(t *testing.T) {
+
+	// ACT
+	_, err := NewByteRangesExtractor([]Range{{Start: 1, End: 3}, {Start: 3, End: 4}})
 
 	// ASSERT
-	require.Error(t, err)
-	pathErr := err.(*os.PathError)
-	assert.Equal(t, output, pathErr.Path)
-	assert.Equal(t, "open", pathErr.Op)
+	assert.EqualError(t, err, "ranges must be in ascending order without overlap: 1-3, 3-4")
 }
 
-func TestNewByteExtractor_InvalidRange_Start(t *testing.T) {
+func TestNewByteRangesExtractor_InvalidRange(t *testing.T) {
 
 	// ACT
-	_, err := NewByteExtractor(0, 10)
+	_, err := NewByteRangesExtractor([]Range{{Start: 1, End: 3}, {Start: 5, End: 4}})
 
 	// ASSERT
-	assert.EqualError(t, err, "invalid range: start = 0, end = 10")
+	assert.EqualError(t, err, "invalid range: start = 5, end = 4")
 }
 
-func TestNewByteExtractor_InvalidRange_End(t *testing.T) {
+func TestNewByteCharBoundaryExtractor(t *testing.T) {
+
+	// "a"は1バイト目、"あ"は2-4バイト目、"い"は5-7バイト目、"b"は8バイト目
+	tests := []struct {
+		start    int64
+		end      int64
+		expected string
+	}{
+		{start: 1, end: 8, expected: "aあいb"},
+		{start: 1, end: 6, expected: "aあ"},
+		{start: 3, end: 7, expected: "い"},
+		{start: 2, end: 3, expected: ""},
+		{start: 5, end: math.MaxInt64, expected: "いb"},
+		{start: -4, end: -1, expected: "いb"},
+		{start: -5, end: -1, expected: "いb"},
+		{start: 2, end: -2, expected: "あい"},
+		{start: -7, end: 3, expected: ""},
+		{start: -100, end: 4, expected: "aあ"},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		extractor, err := NewByteCharBoundaryExtractor(tt.start, tt.end, "UTF-8")
+		require.NoError(t, err)
+
+		output := new(bytes.Buffer)
+
+		// ACT
+		err = extractor.Extract(bytes.NewReader([]byte("aあいb")), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, output.String(), "start = %d, end = %d", tt.start, tt.end)
+	}
+}
+
+func TestNewByteCharBoundaryExtractor_SJIS(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewByteCharBoundaryExtractor(2, 6, "sjis")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
 
 	// ACT
-	_, err := NewByteExtractor(10, 9)
+	err = extractor.Extract(bytes.NewReader(test.StringToByte(t, "あいaうえ", japanese.ShiftJIS)), output)
 
 	// ASSERT
-	assert.EqualError(t, err, "invalid range: start = 10, end = 9")
+	require.NoError(t, err)
+	assert.Equal(t, "いa", test.ByteToString(t, output.Bytes(), japanese.ShiftJIS))
+}
+
+func TestNewByteCharBoundaryExtractor_UTF16(t *testing.T) {
+
+	// ARRANGE
+	encoding := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+
+	extractor, err := NewByteCharBoundaryExtractor(1, 5, "utf-16le")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(bytes.NewReader(test.StringToByte(t, "a𠮷b", encoding)), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	// サロゲートペアは4バイトで1文字
+	assert.Equal(t, "a", test.ByteToString(t, output.Bytes(), encoding))
+}
+
+func TestNewByteCharBoundaryRangesExtractor(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewByteCharBoundaryRangesExtractor([]Range{{Start: 1, End: 2}, {Start: 4, End: 8}, {Start: 9, End: 11}}, "UTF-8")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(bytes.NewReader([]byte("aあいbう")), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "aいbう", output.String())
+}
+
+func TestNewByteCharBoundaryExtractor_InvalidEncoding(t *testing.T) {
+
+	// ACT
+	_, err := NewByteCharBoundaryExtractor(1, 2, "utf")
+
+	// ASSERT
+	assert.EqualError(t, err, "utf is invalid: htmlindex: invalid encoding name")
 }
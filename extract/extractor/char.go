@@ -2,26 +2,25 @@ package extractor
 
 import (
 	"bufio"
-	"fmt"
 	"io"
-	"os"
 
 	enc "github.com/onozaty/filep/encoding"
 
 	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
 )
 
 type charExtractor struct {
-	start    int64
-	end      int64
+	ranges   []Range
+	unit     CharUnit
 	encoding encoding.Encoding
 }
 
-func NewCharExtractor(start int64, end int64, encodingName string) (Extractor, error) {
+func NewCharExtractor(start int64, end int64, unit CharUnit, encodingName string) (Extractor, error) {
 
-	if start < 1 || end < start {
-		return nil, fmt.Errorf("invalid range: start = %d, end = %d", start, end)
+	if err := validateRange(start, end); err != nil {
+		return nil, err
 	}
 
 	encoding, err := enc.Encoding(encodingName)
@@ -30,32 +29,44 @@ func NewCharExtractor(start int64, end int64, encodingName string) (Extractor, e
 	}
 
 	return &charExtractor{
-		start:    start,
-		end:      end,
+		ranges:   []Range{{Start: start, End: end}},
+		unit:     unit,
 		encoding: encoding,
 	}, nil
 }
 
-func (t *charExtractor) Extract(inputFilePath string, outputFilePath string) error {
+func NewCharRangesExtractor(ranges []Range, unit CharUnit, encodingName string) (Extractor, error) {
 
-	input, err := os.Open(inputFilePath)
-	if err != nil {
-		return err
+	if err := validateRanges(ranges); err != nil {
+		return nil, err
 	}
-	defer input.Close()
 
-	out, err := os.Create(outputFilePath)
+	encoding, err := enc.Encoding(encodingName)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	return &charExtractor{
+		ranges:   ranges,
+		unit:     unit,
+		encoding: encoding,
+	}, nil
+}
+
+func (t *charExtractor) Extract(input io.Reader, output io.Writer) error {
+
+	if len(t.ranges) == 1 && isFromEnd(t.ranges[0].Start, t.ranges[0].End) {
+		return t.extractFromEnd(input, output, t.ranges[0])
 	}
-	defer out.Close()
 
 	reader := bufio.NewReader(transform.NewReader(input, t.encoding.NewDecoder()))
-	writer := bufio.NewWriter(transform.NewWriter(out, t.encoding.NewEncoder()))
+	writer := bufio.NewWriter(transform.NewWriter(output, t.encoding.NewEncoder()))
+	next := t.unit.newReader(reader)
 
-	// 指定範囲を取り出し
-	for currentCharNum := int64(1); currentCharNum <= t.end; currentCharNum++ {
-		c, _, err := reader.ReadRune()
+	// 指定範囲を順に取り出し
+	rangeIndex := 0
+	for currentCharNum := int64(1); rangeIndex < len(t.ranges); currentCharNum++ {
+		c, err := next()
 		if err == io.EOF {
 			// 終端ならばそこまでで終了
 			break
@@ -64,12 +75,43 @@ func (t *charExtractor) Extract(inputFilePath string, outputFilePath string) err
 			return err
 		}
 
-		if currentCharNum >= t.start {
+		r := t.ranges[rangeIndex]
+		if currentCharNum >= r.Start {
 			// 開始位置を満たしていたら出力
-			if _, err := writer.WriteRune(c); err != nil {
+			if _, err := writer.WriteString(c); err != nil {
 				return err
 			}
 		}
+
+		if currentCharNum == r.End {
+			// 終了位置に達したら次の範囲へ
+			rangeIndex++
+		}
+	}
+
+	return writer.Flush()
+}
+
+func (t *charExtractor) extractFromEnd(input io.Reader, output io.Writer, r Range) error {
+
+	// UTF-8であれば文字の先頭をバイトで判断できるので、末尾から探せる
+	if t.encoding == unicode.UTF8 && t.unit == CharUnitRune {
+		if seeker, base, size, ok := seekableSize(input); ok {
+			return newUTF8CharScanner(seeker, base, size).extract(output, r.Start, r.End)
+		}
+	}
+
+	reader := bufio.NewReader(transform.NewReader(input, t.encoding.NewDecoder()))
+	writer := bufio.NewWriter(transform.NewWriter(output, t.encoding.NewEncoder()))
+
+	next := t.unit.newReader(reader)
+	write := func(c string) error {
+		_, err := writer.WriteString(c)
+		return err
+	}
+
+	if err := extractUnits(next, write, r.Start, r.End); err != nil {
+		return err
 	}
 
 	return writer.Flush()
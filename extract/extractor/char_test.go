@@ -1,13 +1,17 @@
 package extractor
 
 import (
-	"os"
+	"bytes"
+	"io"
+	"math"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/onozaty/filep/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/htmlindex"
 	"golang.org/x/text/encoding/japanese"
 )
 
@@ -23,8 +27,8 @@ func TestNewCharExtractor(t *testing.T) {
 		output := filepath.Join(d, "output1-10")
 
 		// ACT
-		extractor, _ := NewCharExtractor(1, 10, "UTF-8")
-		err := extractor.Extract(input, output)
+		extractor, _ := NewCharExtractor(1, 10, CharUnitRune, "UTF-8")
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -37,8 +41,8 @@ func TestNewCharExtractor(t *testing.T) {
 		output := filepath.Join(d, "output2-9")
 
 		// ACT
-		extractor, _ := NewCharExtractor(2, 9, "utf-8")
-		err := extractor.Extract(input, output)
+		extractor, _ := NewCharExtractor(2, 9, CharUnitRune, "utf-8")
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -51,8 +55,8 @@ func TestNewCharExtractor(t *testing.T) {
 		output := filepath.Join(d, "output1-11")
 
 		// ACT
-		extractor, _ := NewCharExtractor(1, 11, "UTF-8")
-		err := extractor.Extract(input, output)
+		extractor, _ := NewCharExtractor(1, 11, CharUnitRune, "UTF-8")
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -63,10 +67,10 @@ func TestNewCharExtractor(t *testing.T) {
 	}
 	{
 		output := filepath.Join(d, "output12-12")
-		extractor, _ := NewCharExtractor(12, 12, "UTF-8")
+		extractor, _ := NewCharExtractor(12, 12, CharUnitRune, "UTF-8")
 
 		// ACT
-		err := extractor.Extract(input, output)
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -89,8 +93,8 @@ func TestNewCharExtractor_SJIS(t *testing.T) {
 		output := filepath.Join(d, "output5-10")
 
 		// ACT
-		extractor, _ := NewCharExtractor(5, 10, "SJIS")
-		err := extractor.Extract(input, output)
+		extractor, _ := NewCharExtractor(5, 10, CharUnitRune, "SJIS")
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -101,10 +105,10 @@ func TestNewCharExtractor_SJIS(t *testing.T) {
 	}
 	{
 		output := filepath.Join(d, "output1-9")
-		extractor, _ := NewCharExtractor(1, 9, "sjis")
+		extractor, _ := NewCharExtractor(1, 9, CharUnitRune, "sjis")
 
 		// ACT
-		err := extractor.Extract(input, output)
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -117,8 +121,8 @@ func TestNewCharExtractor_SJIS(t *testing.T) {
 		output := filepath.Join(d, "output10-11")
 
 		// ACT
-		extractor, _ := NewCharExtractor(10, 11, "SJIS")
-		err := extractor.Extract(input, output)
+		extractor, _ := NewCharExtractor(10, 11, CharUnitRune, "SJIS")
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -129,10 +133,10 @@ func TestNewCharExtractor_SJIS(t *testing.T) {
 	}
 	{
 		output := filepath.Join(d, "output15-16")
-		extractor, _ := NewCharExtractor(15, 16, "SJIS")
+		extractor, _ := NewCharExtractor(15, 16, CharUnitRune, "SJIS")
 
 		// ACT
-		err := extractor.Extract(input, output)
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -143,68 +147,161 @@ func TestNewCharExtractor_SJIS(t *testing.T) {
 	}
 }
 
-func TestNewCharExtractor_InputFileNotFound(t *testing.T) {
-
-	// ARRANGE
-	d := t.TempDir()
-
-	input := filepath.Join(d, "xxxx")
-	output := filepath.Join(d, "output")
+func TestNewCharExtractor_InvalidEncoding(t *testing.T) {
 
 	// ACT
-	extractor, _ := NewCharExtractor(1, 9, "UTF-8")
-	err := extractor.Extract(input, output)
+	_, err := NewCharExtractor(1, 9, CharUnitRune, "utf")
 
 	// ASSERT
 	require.Error(t, err)
-	pathErr := err.(*os.PathError)
-	assert.Equal(t, input, pathErr.Path)
-	assert.Equal(t, "open", pathErr.Op)
+	assert.EqualError(t, err, "utf is invalid: htmlindex: invalid encoding name")
 }
 
-func TestNewCharExtractor_OutputFileNotFound(t *testing.T) {
+func TestNewCharExtractor_InvalidRange_Start(t *testing.T) {
 
-	// ARRANGE
-	d := t.TempDir()
+	// ACT
+	_, err := NewCharExtractor(0, 10, CharUnitRune, "utf-8")
 
-	input := test.CreateFileWriteString(t, d, "input", "")
-	output := filepath.Join(d, "non", "output")
+	// ASSERT
+	assert.EqualError(t, err, "invalid range: start = 0, end = 10")
+}
+
+func TestNewCharExtractor_InvalidRange_End(t *testing.T) {
 
 	// ACT
-	extractor, _ := NewCharExtractor(1, 9, "UTF-8")
-	err := extractor.Extract(input, output)
+	_, err := NewCharExtractor(10, 9, CharUnitRune, "utf-8")
 
 	// ASSERT
-	require.Error(t, err)
-	pathErr := err.(*os.PathError)
-	assert.Equal(t, output, pathErr.Path)
-	assert.Equal(t, "open", pathErr.Op)
+	assert.EqualError(t, err, "invalid range: start = 10, end = 9")
 }
 
-func TestNewCharExtractor_InvalidEncoding(t *testing.T) {
+func TestNewCharExtractor_FromEnd(t *testing.T) {
+
+	contents := "あいうabc"
+
+	tests := []struct {
+		start    int64
+		end      int64
+		expected string
+	}{
+		{start: -2, end: math.MaxInt64, expected: "bc"},
+		{start: -4, end: -3, expected: "うa"},
+		{start: 2, end: -5, expected: "い"},
+		{start: -10, end: 1, expected: "あ"},
+		{start: -1, end: 1, expected: ""},
+	}
+
+	for _, encoding := range []string{"UTF-8", "sjis"} {
+		input := test.StringToByte(t, contents, japanese.ShiftJIS)
+		if encoding == "UTF-8" {
+			input = []byte(contents)
+		}
+
+		for _, tt := range tests {
+			extractor, err := NewCharExtractor(tt.start, tt.end, CharUnitRune, encoding)
+			require.NoError(t, err)
+
+			// シーク可能な場合
+			{
+				output := new(bytes.Buffer)
+				err := extractor.Extract(bytes.NewReader(input), output)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, decode(t, output.Bytes(), encoding), "encoding=%s, start=%d, end=%d", encoding, tt.start, tt.end)
+			}
+			// シークできない場合
+			{
+				output := new(bytes.Buffer)
+				err := extractor.Extract(io.MultiReader(bytes.NewReader(input)), output)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, decode(t, output.Bytes(), encoding), "encoding=%s, start=%d, end=%d", encoding, tt.start, tt.end)
+			}
+		}
+	}
+}
+
+func decode(t *testing.T, b []byte, encoding string) string {
+
+	if encoding == "sjis" {
+		return test.ByteToString(t, b, japanese.ShiftJIS)
+	}
+	return string(b)
+}
+
+func TestNewCharRangesExtractor(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewCharRangesExtractor([]Range{{Start: 1, End: 1}, {Start: 3, End: 4}, {Start: 7, End: math.MaxInt64}}, CharUnitRune, "sjis")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
 
 	// ACT
-	_, err := NewCharExtractor(1, 9, "utf")
+	err = extractor.Extract(bytes.NewReader(test.StringToByte(t, "あいうえおかきく", japanese.ShiftJIS)), output)
 
 	// ASSERT
-	require.Error(t, err)
-	assert.EqualError(t, err, "utf is invalid: htmlindex: invalid encoding name")
+	require.NoError(t, err)
+	assert.Equal(t, "あうえきく", test.ByteToString(t, output.Bytes(), japanese.ShiftJIS))
 }
 
-func TestNewCharExtractor_InvalidRange_Start(t *testing.T) {
+func TestNewCharRangesExtractor_Empty(t *testing.T) {
 
 	// ACT
-	_, err := NewCharExtractor(0, 10, "utf-8")
+	_, err := NewCharRangesExtractor([]Range{}, CharUnitRune, "UTF-8")
 
 	// ASSERT
-	assert.EqualError(t, err, "invalid range: start = 0, end = 10")
+	assert.EqualError(t, err, "no ranges are specified")
 }
 
-func TestNewCharExtractor_InvalidRange_End(t *testing.T) {
+func TestNewCharExtractor_Grapheme(t *testing.T) {
+
+	contents := "か\u3099き🇯🇵👨\u200d👩\u200d👧e\u0301"
+
+	tests := []struct {
+		start    int64
+		end      int64
+		expected string
+	}{
+		{start: 1, end: 2, expected: "か\u3099き"},
+		{start: 3, end: 4, expected: "🇯🇵👨\u200d👩\u200d👧"},
+		{start: -1, end: -1, expected: "e\u0301"},
+		{start: -3, end: 3, expected: "🇯🇵"},
+		{start: 2, end: -4, expected: "き"},
+	}
+
+	for _, encoding := range []string{"UTF-8", "utf-16le"} {
+		enc, err := htmlindex.Get(encoding)
+		require.NoError(t, err)
+		input := test.StringToByte(t, contents, enc)
+
+		for _, tt := range tests {
+			// ARRANGE
+			extractor, err := NewCharExtractor(tt.start, tt.end, CharUnitGrapheme, encoding)
+			require.NoError(t, err)
+
+			output := new(bytes.Buffer)
+
+			// ACT
+			err = extractor.Extract(bytes.NewReader(input), output)
+
+			// ASSERT
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, test.ByteToString(t, output.Bytes(), enc), "encoding=%s, start=%d, end=%d", encoding, tt.start, tt.end)
+		}
+	}
+}
+
+func TestNewCharRangesExtractor_Grapheme(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewCharRangesExtractor([]Range{{Start: 1, End: 1}, {Start: 3, End: math.MaxInt64}}, CharUnitGrapheme, "UTF-8")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
 
 	// ACT
-	_, err := NewCharExtractor(10, 9, "utf-8")
+	err = extractor.Extract(strings.NewReader("か\u3099き🇯🇵e\u0301"), output)
 
 	// ASSERT
-	assert.EqualError(t, err, "invalid range: start = 10, end = 9")
+	require.NoError(t, err)
+	assert.Equal(t, "か\u3099🇯🇵e\u0301", output.String())
 }
@@ -0,0 +1,81 @@
+package extractor
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// 文字として数える単位です。
+type CharUnit int
+
+const (
+	// Unicodeのコードポイント
+	CharUnitRune CharUnit = iota
+	// 書記素クラスタ(UAX #29)で、結合文字や絵文字のZWJシーケンスなどをまとめて1文字とする
+	CharUnitGrapheme
+)
+
+// 1文字ずつ読み込む関数を返します。
+func (u CharUnit) newReader(reader *bufio.Reader) func() (string, error) {
+
+	if u == CharUnitGrapheme {
+		return newGraphemeReader(reader).read
+	}
+
+	return func() (string, error) {
+		c, _, err := reader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		return string(c), nil
+	}
+}
+
+// 書記素クラスタ単位で読み込むためのものです。
+type graphemeReader struct {
+	reader *bufio.Reader
+	// 書記素クラスタの区切りが確定していない内容
+	pending []byte
+	state   int
+}
+
+func newGraphemeReader(reader *bufio.Reader) *graphemeReader {
+
+	return &graphemeReader{
+		reader: reader,
+		state:  -1,
+	}
+}
+
+func (g *graphemeReader) read() (string, error) {
+
+	for {
+		// 次の文字まで読み込めば、区切りが確定する
+		cluster, rest, _, state := uniseg.FirstGraphemeCluster(g.pending, g.state)
+		if len(cluster) > 0 && len(rest) > 0 {
+			result := string(cluster)
+			g.pending = append(g.pending[:0], rest...)
+			g.state = state
+			return result, nil
+		}
+
+		c, _, err := g.reader.ReadRune()
+		if err == io.EOF {
+			if len(g.pending) == 0 {
+				return "", io.EOF
+			}
+			// 終端まで読み込んだら、残りが最後の書記素クラスタ
+			last := string(g.pending)
+			g.pending = g.pending[:0]
+			return last, nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		g.pending = utf8.AppendRune(g.pending, c)
+	}
+}
@@ -0,0 +1,56 @@
+package extractor
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCharUnit_NewReader(t *testing.T) {
+
+	// "か"+結合文字の濁点、国旗(Regional Indicatorの組)、ZWJシーケンス、CRLF
+	input := "か\u3099🇯🇵🇺🇸👨\u200d👩\u200d👧a\r\nb"
+
+	tests := []struct {
+		name     string
+		unit     CharUnit
+		expected []string
+	}{
+		{
+			name:     "rune",
+			unit:     CharUnitRune,
+			expected: []string{"か", "\u3099", "🇯", "🇵", "🇺", "🇸", "👨", "\u200d", "👩", "\u200d", "👧", "a", "\r", "\n", "b"},
+		},
+		{
+			name:     "grapheme",
+			unit:     CharUnitGrapheme,
+			expected: []string{"か\u3099", "🇯🇵", "🇺🇸", "👨\u200d👩\u200d👧", "a", "\r\n", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// ARRANGE
+			next := tt.unit.newReader(bufio.NewReader(strings.NewReader(input)))
+
+			// ACT
+			chars := []string{}
+			for {
+				c, err := next()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				chars = append(chars, c)
+			}
+
+			// ASSERT
+			assert.Equal(t, tt.expected, chars)
+		})
+	}
+}
@@ -1,5 +1,35 @@
 package extractor
 
+import (
+	"fmt"
+	"io"
+)
+
 type Extractor interface {
-	Extract(inputFilePath string, outputFilePath string) error
+	Extract(input io.Reader, output io.Writer) error
+}
+
+// 取り出す範囲(1から数えた位置で、開始位置と終了位置を含む)です。
+type Range struct {
+	Start int64
+	End   int64
+}
+
+// 複数の範囲は、昇順で重なりが無いことを確認します。
+func validateRanges(ranges []Range) error {
+
+	if len(ranges) == 0 {
+		return fmt.Errorf("no ranges are specified")
+	}
+
+	for i, r := range ranges {
+		if r.Start < 1 || r.End < r.Start {
+			return fmt.Errorf("invalid range: start = %d, end = %d", r.Start, r.End)
+		}
+		if i > 0 && r.Start <= ranges[i-1].End {
+			return fmt.Errorf("ranges must be in ascending order without overlap: %d-%d, %d-%d", ranges[i-1].Start, ranges[i-1].End, r.Start, r.End)
+		}
+	}
+
+	return nil
 }
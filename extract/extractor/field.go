@@ -0,0 +1,142 @@
+package extractor
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	enc "github.com/onozaty/filep/encoding"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+type fieldExtractor struct {
+	fields    []Range
+	delimiter string
+	csv       bool
+	newline   Newline
+	encoding  encoding.Encoding
+}
+
+// NewFieldExtractor は、各行を区切り文字で分割したフィールドのうち、指定されたものを取り出すExtractorを作成します。
+// csvの場合は、ダブルクォートで囲まれた中の区切り文字と改行では分割しません。
+func NewFieldExtractor(fields []Range, delimiter string, csv bool, newline Newline, encodingName string) (Extractor, error) {
+
+	if err := validateRanges(fields); err != nil {
+		return nil, err
+	}
+
+	if delimiter == "" {
+		return nil, fmt.Errorf("delimiter must be specified")
+	}
+
+	encoding, err := enc.Encoding(encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fieldExtractor{
+		fields:    fields,
+		delimiter: delimiter,
+		csv:       csv,
+		newline:   newline,
+		encoding:  encoding,
+	}, nil
+}
+
+func (t *fieldExtractor) Extract(input io.Reader, output io.Writer) error {
+
+	reader := bufio.NewReader(transform.NewReader(input, t.encoding.NewDecoder()))
+	writer := bufio.NewWriter(transform.NewWriter(output, t.encoding.NewEncoder()))
+	newline := t.newline.newMatcher(reader)
+
+	for {
+		record, err := t.readRecord(newline)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if record == "" {
+			break
+		}
+
+		// 改行は元のまま残す
+		content, terminator := t.newline.cut(record)
+
+		if _, err := writer.WriteString(t.selectFields(content) + terminator); err != nil {
+			return err
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	return writer.Flush()
+}
+
+// 1レコード分を読み込みます。
+// CSVの場合は、クォートの途中で改行された場合に次の行もあわせて1レコードとします。
+func (t *fieldExtractor) readRecord(newline *newlineMatcher) (string, error) {
+
+	record, err := newline.readLine()
+	if !t.csv {
+		return record, err
+	}
+
+	for err == nil && strings.Count(record, `"`)%2 != 0 {
+		var next string
+		next, err = newline.readLine()
+		record += next
+	}
+
+	return record, err
+}
+
+func (t *fieldExtractor) selectFields(content string) string {
+
+	values := t.split(content)
+	if len(values) == 1 {
+		// 区切り文字が無い行はそのまま
+		return content
+	}
+
+	selected := []string{}
+	for _, field := range t.fields {
+		for i := field.Start; i <= field.End && i <= int64(len(values)); i++ {
+			selected = append(selected, values[i-1])
+		}
+	}
+
+	return strings.Join(selected, t.delimiter)
+}
+
+func (t *fieldExtractor) split(content string) []string {
+
+	if !t.csv {
+		return strings.Split(content, t.delimiter)
+	}
+
+	// ダブルクォートで囲まれた中の区切り文字では分割しない
+	// (エスケープされた "" は2回切り替わるので、結果として囲まれたままになる)
+	values := []string{}
+	inQuotes := false
+	start := 0
+	for i := 0; i < len(content); {
+		if content[i] == '"' {
+			inQuotes = !inQuotes
+			i++
+			continue
+		}
+		if !inQuotes && strings.HasPrefix(content[i:], t.delimiter) {
+			values = append(values, content[start:i])
+			i += len(t.delimiter)
+			start = i
+			continue
+		}
+		i++
+	}
+
+	return append(values, content[start:])
+}
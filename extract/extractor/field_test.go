@@ -0,0 +1,129 @@
+package extractor
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestNewFieldExtractor(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewFieldExtractor([]Range{{Start: 2, End: 2}, {Start: 4, End: math.MaxInt64}}, "\t", false, NewlineLF, "UTF-8")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("a\tb\tc\td\te\r\n1\t2\n\nxyz\nA\tB"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	// 区切り文字の無い行はそのまま、足りないフィールドは無視
+	assert.Equal(t, "b\td\te\r\n2\n\nxyz\nB", output.String())
+}
+
+func TestNewFieldExtractor_MultiCharDelimiter(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewFieldExtractor([]Range{{Start: 1, End: 1}, {Start: 3, End: 3}}, "::", false, NewlineLF, "UTF-8")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("a::b:c::d\n"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "a::d\n", output.String())
+}
+
+func TestNewFieldExtractor_SJIS(t *testing.T) {
+
+	// ARRANGE
+	// "、"はShift_JISで 0x81 0x41 となり、2バイト目が"A"と同じ
+	extractor, err := NewFieldExtractor([]Range{{Start: 2, End: 3}}, "、", false, NewlineLF, "sjis")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(bytes.NewReader(test.StringToByte(t, "AあA、いA、うA、えA\n", japanese.ShiftJIS)), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "いA、うA\n", test.ByteToString(t, output.Bytes(), japanese.ShiftJIS))
+}
+
+func TestNewFieldExtractor_CSV(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewFieldExtractor([]Range{{Start: 2, End: 3}}, ",", true, NewlineLF, "UTF-8")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("a,\"b,1\",\"c\"\"\",d\r\ne,\"f\r\ng\",h,i\n"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "\"b,1\",\"c\"\"\"\r\n\"f\r\ng\",h\n", output.String())
+}
+
+func TestNewFieldExtractor_NotCSV(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewFieldExtractor([]Range{{Start: 2, End: 2}}, ",", false, NewlineLF, "UTF-8")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("a,\"b,1\",c\n"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "\"b\n", output.String())
+}
+
+func TestNewFieldExtractor_EmptyDelimiter(t *testing.T) {
+
+	// ACT
+	_, err := NewFieldExtractor([]Range{{Start: 1, End: 1}}, "", false, NewlineLF, "UTF-8")
+
+	// ASSERT
+	assert.EqualError(t, err, "delimiter must be specified")
+}
+
+func TestNewFieldExtractor_Overlap(t *testing.T) {
+
+	// ACT
+	_, err := NewFieldExtractor([]Range{{Start: 1, End: 2}, {Start: 2, End: 3}}, ",", false, NewlineLF, "UTF-8")
+
+	// ASSERT
+	assert.EqualError(t, err, "ranges must be in ascending order without overlap: 1-2, 2-3")
+}
+
+func TestNewFieldExtractor_NewlineAny(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewFieldExtractor([]Range{{Start: 2, End: 2}}, ",", true, NewlineAny, "UTF-8")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("a,b\rc,\"d\re\",f\r\ng,h"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "b\r\"d\re\"\r\nh", output.String())
+}
@@ -0,0 +1,154 @@
+package extractor
+
+import (
+	"fmt"
+	"io"
+)
+
+// 先頭を取り出し、切り捨てた場合にのみ末尾にsuffixを付けるExtractorです。
+type headSuffixExtractor struct {
+	num    int64
+	suffix string
+	// suffixを含めて指定された大きさに収める場合に、suffixのために空けておく大きさ
+	reserve int64
+	units   SizedUnits
+}
+
+// NewHeadSuffixExtractor は、単位ごとに先頭から指定された大きさまでを取り出し、切り捨てた場合はsuffixを付けるExtractorを作成します。
+// suffixは単位と同じエンコーディングで出力します。suffixInLimitの場合は、suffixを含めて指定された大きさに収めます。
+func NewHeadSuffixExtractor(num int64, suffix string, suffixInLimit bool, units SizedUnits) (Extractor, error) {
+
+	if num < 0 {
+		return nil, fmt.Errorf("number must be greater than or equal to 0")
+	}
+
+	reserve, err := suffixReserve(suffix, suffixInLimit, num, units)
+	if err != nil {
+		return nil, err
+	}
+
+	return &headSuffixExtractor{
+		num:     num,
+		suffix:  suffix,
+		reserve: reserve,
+		units:   units,
+	}, nil
+}
+
+// suffixを含めて指定された大きさに収める場合に、suffixのために空けておく大きさを返します。
+func suffixReserve(suffix string, suffixInLimit bool, num int64, units SizedUnits) (int64, error) {
+
+	if !suffixInLimit {
+		return 0, nil
+	}
+
+	size, err := units.size(suffix)
+	if err != nil {
+		return 0, err
+	}
+	if size > num {
+		return 0, fmt.Errorf("suffix must not exceed the number")
+	}
+
+	return size, nil
+}
+
+func (t *headSuffixExtractor) Extract(input io.Reader, output io.Writer) error {
+
+	if t.units.newReader == nil {
+		return t.extractBytes(input, output)
+	}
+
+	next, writer := t.units.open(input, output)
+
+	write := func(value string) error {
+		_, err := writer.WriteString(value)
+		return err
+	}
+
+	truncated, err := extractHead(next, write, t.num, t.reserve)
+	if err != nil {
+		return err
+	}
+
+	if truncated {
+		if err := t.writeSuffix(writer); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+func (t *headSuffixExtractor) extractBytes(input io.Reader, output io.Writer) error {
+
+	if _, err := io.CopyN(output, input, t.num-t.reserve); err != nil {
+		if err == io.EOF { // 入力ファイルが指定サイズ未満の場合
+			return nil
+		}
+		return err
+	}
+
+	// 空けておいた分と、その次の1バイトを読み込めれば、指定サイズを超えている
+	rest := make([]byte, t.reserve+1)
+	n, err := io.ReadFull(input, rest)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		_, err := output.Write(rest[:n])
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	return t.writeSuffix(output)
+}
+
+func (t *headSuffixExtractor) writeSuffix(output io.Writer) error {
+
+	suffix, err := t.units.convert(t.suffix)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(output, suffix)
+	return err
+}
+
+// 大きさを持つ単位ごとに読み込みながら、numまでに全体が収まる単位を取り出し、numを超えて切り捨てたかを返します。
+// 切り捨てる場合は、reserveの分を空けるように num-reserve までに収まる単位までとします。
+func extractHead[T any](next func() (T, int64, error), write func(T) error, num int64, reserve int64) (bool, error) {
+
+	// 切り捨てるかどうか決まるまで、空けておく分に含まれるものは保持しておく
+	pending := []T{}
+	var total int64
+	for {
+		value, size, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return false, err
+		}
+		total += size
+
+		if total > num {
+			return true, nil
+		}
+
+		if total <= num-reserve {
+			if err := write(value); err != nil {
+				return false, err
+			}
+		} else {
+			pending = append(pending, value)
+		}
+	}
+
+	for _, value := range pending {
+		if err := write(value); err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,189 @@
+package extractor
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestNewCharHeadSuffixExtractor(t *testing.T) {
+
+	tests := []struct {
+		charNum       int64
+		suffixInLimit bool
+		expected      string
+	}{
+		{charNum: 3, suffixInLimit: false, expected: "あいう…"},
+		{charNum: 3, suffixInLimit: true, expected: "あい…"},
+		{charNum: 5, suffixInLimit: false, expected: "あいうえお"},
+		{charNum: 5, suffixInLimit: true, expected: "あいうえお"},
+		{charNum: 4, suffixInLimit: true, expected: "あいう…"},
+		{charNum: 1, suffixInLimit: true, expected: "…"},
+		{charNum: 0, suffixInLimit: false, expected: "…"},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		units, err := NewCharUnits(CharUnitRune, "UTF-8")
+		require.NoError(t, err)
+		extractor, err := NewHeadSuffixExtractor(tt.charNum, "…", tt.suffixInLimit, units)
+		require.NoError(t, err)
+
+		output := new(bytes.Buffer)
+
+		// ACT
+		err = extractor.Extract(strings.NewReader("あいうえお"), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, output.String(), "charNum=%d, suffixInLimit=%v", tt.charNum, tt.suffixInLimit)
+	}
+}
+
+func TestNewCharHeadSuffixExtractor_Empty(t *testing.T) {
+
+	// ARRANGE
+	units, err := NewCharUnits(CharUnitRune, "UTF-8")
+	require.NoError(t, err)
+	extractor, err := NewHeadSuffixExtractor(0, "…", false, units)
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader(""), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "", output.String())
+}
+
+func TestNewByteHeadSuffixExtractor(t *testing.T) {
+
+	tests := []struct {
+		byteNum       int64
+		suffixInLimit bool
+		expected      string
+	}{
+		{byteNum: 4, suffixInLimit: false, expected: "0123..."},
+		{byteNum: 4, suffixInLimit: true, expected: "0..."},
+		{byteNum: 6, suffixInLimit: true, expected: "012345"},
+		{byteNum: 5, suffixInLimit: true, expected: "01..."},
+		{byteNum: 3, suffixInLimit: true, expected: "..."},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		units, err := NewByteUnits("UTF-8")
+		require.NoError(t, err)
+		extractor, err := NewHeadSuffixExtractor(tt.byteNum, "...", tt.suffixInLimit, units)
+		require.NoError(t, err)
+
+		output := new(bytes.Buffer)
+
+		// ACT
+		err = extractor.Extract(io.MultiReader(strings.NewReader("012345")), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, output.String(), "byteNum=%d, suffixInLimit=%v", tt.byteNum, tt.suffixInLimit)
+	}
+}
+
+func TestNewByteCharBoundaryHeadSuffixExtractor_SJIS(t *testing.T) {
+
+	// ARRANGE
+	// "…"はShift_JISで2バイト
+	units, err := NewByteCharBoundaryUnits("sjis")
+	require.NoError(t, err)
+	extractor, err := NewHeadSuffixExtractor(7, "…", true, units)
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(bytes.NewReader(test.StringToByte(t, "aあいうえお", japanese.ShiftJIS)), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "aあい…", test.ByteToString(t, output.Bytes(), japanese.ShiftJIS))
+}
+
+func TestNewLineHeadSuffixExtractor(t *testing.T) {
+
+	tests := []struct {
+		lineNum       int64
+		suffix        string
+		suffixInLimit bool
+		expected      string
+	}{
+		{lineNum: 2, suffix: "...\n", suffixInLimit: false, expected: "1\n2\n...\n"},
+		{lineNum: 2, suffix: "...\n", suffixInLimit: true, expected: "1\n...\n"},
+		{lineNum: 3, suffix: "...\n", suffixInLimit: true, expected: "1\n2\n3"},
+		{lineNum: 2, suffix: "[cut]", suffixInLimit: false, expected: "1\n2\n[cut]"},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		units, err := NewLineUnits(NewlineLF, "UTF-8")
+		require.NoError(t, err)
+		extractor, err := NewHeadSuffixExtractor(tt.lineNum, tt.suffix, tt.suffixInLimit, units)
+		require.NoError(t, err)
+
+		output := new(bytes.Buffer)
+
+		// ACT
+		err = extractor.Extract(strings.NewReader("1\n2\n3"), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, output.String(), "lineNum=%d, suffix=%s, suffixInLimit=%v", tt.lineNum, tt.suffix, tt.suffixInLimit)
+	}
+}
+
+func TestNewWidthHeadSuffixExtractor(t *testing.T) {
+
+	// ARRANGE
+	units, err := NewWidthUnits(1, "UTF-8")
+	require.NoError(t, err)
+	extractor, err := NewHeadSuffixExtractor(6, "…", true, units)
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("あいうえ"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	// "…"は曖昧な幅の文字なので1桁として扱う
+	assert.Equal(t, "あい…", output.String())
+}
+
+func TestNewHeadSuffixExtractor_Invalid(t *testing.T) {
+
+	{
+		// ACT
+		units, err := NewCharUnits(CharUnitRune, "UTF-8")
+		require.NoError(t, err)
+		_, err = NewHeadSuffixExtractor(1, "...", true, units)
+
+		// ASSERT
+		assert.EqualError(t, err, "suffix must not exceed the number")
+	}
+	{
+		// ACT
+		units, err := NewByteUnits("UTF-8")
+		require.NoError(t, err)
+		_, err = NewHeadSuffixExtractor(-1, "...", false, units)
+
+		// ASSERT
+		assert.EqualError(t, err, "number must be greater than or equal to 0")
+	}
+}
@@ -0,0 +1,194 @@
+package extractor
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// 先頭と末尾を取り出し、その間に省略したことを示すマーカーを出力するExtractorです。
+type headTailExtractor struct {
+	head int64
+	tail int64
+	// 省略した大きさを%dに埋め込んで出力
+	marker string
+	units  SizedUnits
+}
+
+// NewHeadTailExtractor は、単位ごとに先頭と末尾を取り出し、その間を省略した場合はマーカーを出力するExtractorを作成します。
+// マーカーは単位と同じエンコーディングで出力します。
+func NewHeadTailExtractor(head int64, tail int64, marker string, units SizedUnits) (Extractor, error) {
+
+	if head < 0 || tail < 0 {
+		return nil, fmt.Errorf("number must be greater than or equal to 0")
+	}
+
+	return &headTailExtractor{
+		head:   head,
+		tail:   tail,
+		marker: marker,
+		units:  units,
+	}, nil
+}
+
+func (t *headTailExtractor) Extract(input io.Reader, output io.Writer) error {
+
+	if t.units.newReader == nil {
+		return t.extractBytes(input, output)
+	}
+
+	next, writer := t.units.open(input, output)
+
+	write := func(value string) error {
+		_, err := writer.WriteString(value)
+		return err
+	}
+
+	writeMarker := func(omitted int64, last string) error {
+		// 行単位の場合は、マーカーも1行として扱う
+		_, terminator := t.units.newline.cut(last)
+		marker, err := t.units.convert(t.formatMarker(omitted) + terminator)
+		if err != nil {
+			return err
+		}
+
+		return write(marker)
+	}
+
+	if err := extractHeadTail(next, write, writeMarker, t.head, t.tail); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+func (t *headTailExtractor) extractBytes(input io.Reader, output io.Writer) error {
+
+	if _, err := io.CopyN(output, input, t.head); err != nil {
+		if err == io.EOF { // 先頭部分に収まった場合
+			return nil
+		}
+		return err
+	}
+
+	writeMarker := func(omitted int64) error {
+		marker, err := t.units.convert(t.formatMarker(omitted))
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(output, marker)
+		return err
+	}
+
+	if seeker, base, size, ok := seekableSize(input); ok {
+		// 残りのサイズが分かれば、末尾部分の位置までシークできる
+		if size > t.tail {
+			if err := writeMarker(size - t.tail); err != nil {
+				return err
+			}
+			if _, err := seeker.Seek(base+size-t.tail, io.SeekStart); err != nil {
+				return err
+			}
+		}
+
+		_, err := io.Copy(output, seeker)
+		return err
+	}
+
+	// 末尾部分になり得るものだけを保持しながら最後まで読み込む
+	buf := []byte{}
+	chunk := make([]byte, 32*1024)
+	var omitted int64
+	for {
+		n, err := input.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+
+		// 毎回詰めなおさないように、ある程度たまってから捨てる
+		if over := int64(len(buf)) - t.tail; over > 0 && (err == io.EOF || over > t.tail+int64(len(chunk))) {
+			omitted += over
+			buf = append(buf[:0], buf[over:]...)
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if omitted > 0 {
+		if err := writeMarker(omitted); err != nil {
+			return err
+		}
+	}
+
+	_, err := output.Write(buf)
+	return err
+}
+
+func (t *headTailExtractor) formatMarker(omitted int64) string {
+
+	return strings.ReplaceAll(t.marker, "%d", strconv.FormatInt(omitted, 10))
+}
+
+// 大きさを持つ単位ごとに読み込みながら、先頭からheadまでと、末尾からtailまでに全体が収まる単位を取り出します。
+// その間の単位は省略し、省略した大きさと最後に省略した単位をマーカーとして出力します。
+func extractHeadTail[T any](next func() (T, int64, error), write func(T) error, writeMarker func(omitted int64, last T) error, head int64, tail int64) error {
+
+	type unit struct {
+		first int64
+		size  int64
+		value T
+	}
+
+	pending := []unit{}
+	var total int64
+	var omitted int64
+	var omittedUnits int64
+	var lastOmitted T
+	for {
+		value, size, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		first := max(min(total+1, total+size), 1)
+		total += size
+
+		if total <= head {
+			// 先頭部分
+			if err := write(value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		pending = append(pending, unit{first: first, size: size, value: value})
+
+		// 末尾から数えた開始位置より前になったものは省略
+		for len(pending) > 0 && pending[0].first < total-tail+1 {
+			omitted += pending[0].size
+			omittedUnits++
+			lastOmitted = pending[0].value
+			pending = pending[1:]
+		}
+	}
+
+	if omittedUnits > 0 {
+		if err := writeMarker(omitted, lastOmitted); err != nil {
+			return err
+		}
+	}
+
+	for _, u := range pending {
+		if err := write(u.value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
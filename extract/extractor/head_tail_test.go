@@ -0,0 +1,234 @@
+package extractor
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestNewLineHeadTailExtractor(t *testing.T) {
+
+	contents := "1\n2\n3\n4\n5\n6\n"
+
+	tests := []struct {
+		head     int64
+		tail     int64
+		expected string
+	}{
+		{head: 2, tail: 2, expected: "1\n2\n[2 lines]\n5\n6\n"},
+		{head: 1, tail: 0, expected: "1\n[5 lines]\n"},
+		{head: 0, tail: 1, expected: "[5 lines]\n6\n"},
+		{head: 3, tail: 3, expected: "1\n2\n3\n4\n5\n6\n"},
+		{head: 4, tail: 4, expected: "1\n2\n3\n4\n5\n6\n"},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		units, err := NewLineUnits(NewlineLF, "UTF-8")
+		require.NoError(t, err)
+		extractor, err := NewHeadTailExtractor(tt.head, tt.tail, "[%d lines]", units)
+		require.NoError(t, err)
+
+		output := new(bytes.Buffer)
+
+		// ACT
+		err = extractor.Extract(strings.NewReader(contents), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, output.String(), "head=%d, tail=%d", tt.head, tt.tail)
+	}
+}
+
+func TestNewLineHeadTailExtractor_Newline(t *testing.T) {
+
+	// ARRANGE
+	units, err := NewLineUnits(NewlineAny, "UTF-8")
+	require.NoError(t, err)
+	extractor, err := NewHeadTailExtractor(1, 1, "...", units)
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("a\nb\nc\r\nd"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	// マーカーの改行は、最後に省略した行のもの
+	assert.Equal(t, "a\n...\r\nd", output.String())
+}
+
+func TestNewCharHeadTailExtractor(t *testing.T) {
+
+	// ARRANGE
+	units, err := NewCharUnits(CharUnitRune, "UTF-8")
+	require.NoError(t, err)
+	extractor, err := NewHeadTailExtractor(2, 3, "…(%d)…", units)
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("あいうえおかきくけこ"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "あい…(5)…くけこ", output.String())
+}
+
+func TestNewCharHeadTailExtractor_SJIS(t *testing.T) {
+
+	// ARRANGE
+	units, err := NewCharUnits(CharUnitRune, "sjis")
+	require.NoError(t, err)
+	extractor, err := NewHeadTailExtractor(1, 1, "～", units)
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(bytes.NewReader(test.StringToByte(t, "あいうえお", japanese.ShiftJIS)), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "あ～お", test.ByteToString(t, output.Bytes(), japanese.ShiftJIS))
+}
+
+func TestNewWidthHeadTailExtractor(t *testing.T) {
+
+	// ARRANGE
+	units, err := NewWidthUnits(1, "UTF-8")
+	require.NoError(t, err)
+	extractor, err := NewHeadTailExtractor(2, 2, "[%d]", units)
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("aあいうえb"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	// 途中で切れる全角文字は省略した側に含める
+	assert.Equal(t, "a[8]b", output.String())
+}
+
+func TestNewByteHeadTailExtractor(t *testing.T) {
+
+	contents := "0123456789"
+
+	tests := []struct {
+		head     int64
+		tail     int64
+		expected string
+	}{
+		{head: 3, tail: 2, expected: "012<5>89"},
+		{head: 0, tail: 4, expected: "<6>6789"},
+		{head: 4, tail: 0, expected: "0123<6>"},
+		{head: 5, tail: 5, expected: "0123456789"},
+		{head: 10, tail: 3, expected: "0123456789"},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		units, err := NewByteUnits("UTF-8")
+		require.NoError(t, err)
+		extractor, err := NewHeadTailExtractor(tt.head, tt.tail, "<%d>", units)
+		require.NoError(t, err)
+
+		// ACT
+		// ファイル(シーク可能)
+		d := t.TempDir()
+		input := test.CreateFileWriteString(t, d, "input", contents)
+		fileOutput := new(bytes.Buffer)
+		fileErr := extractor.Extract(test.OpenFile(t, input), fileOutput)
+
+		// パイプなど(シーク不可)
+		streamOutput := new(bytes.Buffer)
+		streamErr := extractor.Extract(io.MultiReader(strings.NewReader(contents)), streamOutput)
+
+		// ASSERT
+		require.NoError(t, fileErr)
+		assert.Equal(t, tt.expected, fileOutput.String(), "head=%d, tail=%d", tt.head, tt.tail)
+		require.NoError(t, streamErr)
+		assert.Equal(t, tt.expected, streamOutput.String(), "head=%d, tail=%d", tt.head, tt.tail)
+	}
+}
+
+func TestNewByteHeadTailExtractor_Large(t *testing.T) {
+
+	// ARRANGE
+	contents := strings.Repeat("0123456789", 10000)
+
+	d := t.TempDir()
+	input := filepath.Join(d, "input")
+	require.NoError(t, os.WriteFile(input, []byte(contents), 0644))
+
+	units, err := NewByteUnits("UTF-8")
+	require.NoError(t, err)
+	extractor, err := NewHeadTailExtractor(5, 15, "...", units)
+	require.NoError(t, err)
+
+	// ACT
+	fileOutput := new(bytes.Buffer)
+	fileErr := extractor.Extract(test.OpenFile(t, input), fileOutput)
+
+	streamOutput := new(bytes.Buffer)
+	streamErr := extractor.Extract(io.MultiReader(strings.NewReader(contents)), streamOutput)
+
+	// ASSERT
+	require.NoError(t, fileErr)
+	assert.Equal(t, "01234...567890123456789", fileOutput.String())
+	require.NoError(t, streamErr)
+	assert.Equal(t, "01234...567890123456789", streamOutput.String())
+}
+
+func TestNewByteCharBoundaryHeadTailExtractor(t *testing.T) {
+
+	// ARRANGE
+	units, err := NewByteCharBoundaryUnits("sjis")
+	require.NoError(t, err)
+	extractor, err := NewHeadTailExtractor(4, 4, "…%d…", units)
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(bytes.NewReader(test.StringToByte(t, "aあいうえb", japanese.ShiftJIS)), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	// マーカーもエンコーディングに合わせて出力
+	assert.Equal(t, "aあ…4…えb", test.ByteToString(t, output.Bytes(), japanese.ShiftJIS))
+}
+
+func TestNewHeadTailExtractor_Invalid(t *testing.T) {
+
+	{
+		// ACT
+		units, err := NewLineUnits(NewlineLF, "UTF-8")
+		require.NoError(t, err)
+		_, err = NewHeadTailExtractor(-1, 1, "", units)
+
+		// ASSERT
+		assert.EqualError(t, err, "number must be greater than or equal to 0")
+	}
+	{
+		// ACT
+		units, err := NewByteUnits("UTF-8")
+		require.NoError(t, err)
+		_, err = NewHeadTailExtractor(1, -1, "", units)
+
+		// ASSERT
+		assert.EqualError(t, err, "number must be greater than or equal to 0")
+	}
+}
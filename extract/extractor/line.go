@@ -2,9 +2,7 @@ package extractor
 
 import (
 	"bufio"
-	"fmt"
 	"io"
-	"os"
 
 	enc "github.com/onozaty/filep/encoding"
 
@@ -13,15 +11,15 @@ import (
 )
 
 type lineExtractor struct {
-	start    int64
-	end      int64
+	ranges   []Range
+	newline  Newline
 	encoding encoding.Encoding
 }
 
-func NewLineExtractor(start int64, end int64, encodingName string) (Extractor, error) {
+func NewLineExtractor(start int64, end int64, newline Newline, encodingName string) (Extractor, error) {
 
-	if start < 1 || end < start {
-		return nil, fmt.Errorf("invalid range: start = %d, end = %d", start, end)
+	if err := validateRange(start, end); err != nil {
+		return nil, err
 	}
 
 	encoding, err := enc.Encoding(encodingName)
@@ -30,31 +28,43 @@ func NewLineExtractor(start int64, end int64, encodingName string) (Extractor, e
 	}
 
 	return &lineExtractor{
-		start:    start,
-		end:      end,
+		ranges:   []Range{{Start: start, End: end}},
+		newline:  newline,
 		encoding: encoding,
 	}, nil
 }
 
-func (t *lineExtractor) Extract(inputFilePath string, outputFilePath string) error {
+func NewLineRangesExtractor(ranges []Range, newline Newline, encodingName string) (Extractor, error) {
 
-	input, err := os.Open(inputFilePath)
-	if err != nil {
-		return err
+	if err := validateRanges(ranges); err != nil {
+		return nil, err
 	}
-	defer input.Close()
 
-	out, err := os.Create(outputFilePath)
+	encoding, err := enc.Encoding(encodingName)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	return &lineExtractor{
+		ranges:   ranges,
+		newline:  newline,
+		encoding: encoding,
+	}, nil
+}
+
+func (t *lineExtractor) Extract(input io.Reader, output io.Writer) error {
+
+	if len(t.ranges) == 1 && isFromEnd(t.ranges[0].Start, t.ranges[0].End) {
+		return t.extractFromEnd(input, output, t.ranges[0])
 	}
-	defer out.Close()
 
 	reader := bufio.NewReader(transform.NewReader(input, t.encoding.NewDecoder()))
-	writer := bufio.NewWriter(transform.NewWriter(out, t.encoding.NewEncoder()))
+	writer := bufio.NewWriter(transform.NewWriter(output, t.encoding.NewEncoder()))
+	newline := t.newline.newMatcher(reader)
 
+	rangeIndex := 0
 	currentLineNum := int64(1) // 現在行は1行目から
-	for currentLineNum <= t.end {
+	for rangeIndex < len(t.ranges) {
 		c, _, err := reader.ReadRune()
 		if err == io.EOF {
 			// 終端ならばそこまでで終了
@@ -64,18 +74,57 @@ func (t *lineExtractor) Extract(inputFilePath string, outputFilePath string) err
 			return err
 		}
 
-		if currentLineNum >= t.start {
+		r := t.ranges[rangeIndex]
+		if currentLineNum >= r.Start {
 			// 開始位置を満たしていたら出力
 			if _, err := writer.WriteRune(c); err != nil {
 				return err
 			}
 		}
 
-		if c == '\n' {
-			// LFで行数をインクリメント
+		if newline.push(c) {
+			if currentLineNum == r.End {
+				// 終了位置に達したら次の範囲へ
+				rangeIndex++
+			}
+			// 改行で行数をインクリメント
 			currentLineNum++
 		}
 	}
 
 	return writer.Flush()
 }
+
+func (t *lineExtractor) extractFromEnd(input io.Reader, output io.Writer, r Range) error {
+
+	// 改行をバイトで判断できるエンコーディングであれば、末尾から探せる
+	if newlineByte, ok := t.newline.singleByte(t.encoding); ok {
+		if seeker, base, size, ok := seekableSize(input); ok {
+			return newLineScanner(seeker, base, size, newlineByte).extract(output, r.Start, r.End)
+		}
+	}
+
+	reader := bufio.NewReader(transform.NewReader(input, t.encoding.NewDecoder()))
+	writer := bufio.NewWriter(transform.NewWriter(output, t.encoding.NewEncoder()))
+	newline := t.newline.newMatcher(reader)
+
+	next := func() (string, error) {
+		line, err := newline.readLine()
+		if err == io.EOF && line != "" {
+			// 改行で終わっていない最後の行
+			return line, nil
+		}
+		return line, err
+	}
+
+	write := func(line string) error {
+		_, err := writer.WriteString(line)
+		return err
+	}
+
+	if err := extractUnits(next, write, r.Start, r.End); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
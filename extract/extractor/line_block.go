@@ -0,0 +1,96 @@
+package extractor
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+
+	enc "github.com/onozaty/filep/encoding"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+type lineBlockExtractor struct {
+	from              *regexp.Regexp
+	to                *regexp.Regexp
+	excludeDelimiters bool
+	firstOnly         bool
+	newline           Newline
+	encoding          encoding.Encoding
+}
+
+// NewLineBlockExtractor は、fromにマッチする行からtoにマッチする行までのブロックを取り出すExtractorを作成します。
+// fromが無い場合は先頭から、toが無い場合は末尾までとなります。
+func NewLineBlockExtractor(from *regexp.Regexp, to *regexp.Regexp, excludeDelimiters bool, firstOnly bool, newline Newline, encodingName string) (Extractor, error) {
+
+	if from == nil && to == nil {
+		return nil, fmt.Errorf("from or to must be specified")
+	}
+
+	encoding, err := enc.Encoding(encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lineBlockExtractor{
+		from:              from,
+		to:                to,
+		excludeDelimiters: excludeDelimiters,
+		firstOnly:         firstOnly,
+		newline:           newline,
+		encoding:          encoding,
+	}, nil
+}
+
+func (t *lineBlockExtractor) Extract(input io.Reader, output io.Writer) error {
+
+	reader := bufio.NewReader(transform.NewReader(input, t.encoding.NewDecoder()))
+	writer := bufio.NewWriter(transform.NewWriter(output, t.encoding.NewEncoder()))
+	newline := t.newline.newMatcher(reader)
+
+	// fromが無い場合は先頭からブロックとなる
+	inBlock := t.from == nil
+	for {
+		line, err := newline.readLine()
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if line == "" {
+			break
+		}
+
+		// 改行を除いた内容でマッチさせる
+		content, _ := t.newline.cut(line)
+
+		write := false
+		finished := false
+		if !inBlock {
+			if t.from.MatchString(content) {
+				inBlock = true
+				write = !t.excludeDelimiters
+			}
+		} else if t.to != nil && t.to.MatchString(content) {
+			// 終了の行は、開始の行の次の行から判定
+			inBlock = false
+			write = !t.excludeDelimiters
+			// fromが無い場合は次のブロックが始まることは無い
+			finished = t.firstOnly || t.from == nil
+		} else {
+			write = true
+		}
+
+		if write {
+			if _, err := writer.WriteString(line); err != nil {
+				return err
+			}
+		}
+
+		if finished || err == io.EOF {
+			break
+		}
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,137 @@
+package extractor
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+)
+
+const blockContents = "a\nBEGIN 1\nb\nEND\r\nc\nBEGIN 2\nd\nEND\ne\nBEGIN 3\nf"
+
+func TestNewLineBlockExtractor(t *testing.T) {
+
+	tests := []struct {
+		name              string
+		from              *regexp.Regexp
+		to                *regexp.Regexp
+		excludeDelimiters bool
+		firstOnly         bool
+		expected          string
+	}{
+		{
+			name:     "all",
+			from:     regexp.MustCompile("^BEGIN"),
+			to:       regexp.MustCompile("^END$"),
+			expected: "BEGIN 1\nb\nEND\r\nBEGIN 2\nd\nEND\nBEGIN 3\nf",
+		},
+		{
+			name:              "exclude delimiters",
+			from:              regexp.MustCompile("^BEGIN"),
+			to:                regexp.MustCompile("^END$"),
+			excludeDelimiters: true,
+			expected:          "b\nd\nf",
+		},
+		{
+			name:      "first only",
+			from:      regexp.MustCompile("^BEGIN"),
+			to:        regexp.MustCompile("^END$"),
+			firstOnly: true,
+			expected:  "BEGIN 1\nb\nEND\r\n",
+		},
+		{
+			name:     "from only",
+			from:     regexp.MustCompile("2$"),
+			expected: "BEGIN 2\nd\nEND\ne\nBEGIN 3\nf",
+		},
+		{
+			name:              "to only",
+			to:                regexp.MustCompile("^END"),
+			excludeDelimiters: true,
+			expected:          "a\nBEGIN 1\nb\n",
+		},
+		{
+			name:     "not found",
+			from:     regexp.MustCompile("^X"),
+			to:       regexp.MustCompile("^END"),
+			expected: "",
+		},
+		{
+			// 開始の行は終了の判定対象にならない
+			name:     "same line",
+			from:     regexp.MustCompile("^BEGIN"),
+			to:       regexp.MustCompile("^(BEGIN|END)"),
+			expected: "BEGIN 1\nb\nEND\r\nBEGIN 2\nd\nEND\nBEGIN 3\nf",
+		},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		extractor, err := NewLineBlockExtractor(tt.from, tt.to, tt.excludeDelimiters, tt.firstOnly, NewlineLF, "UTF-8")
+		require.NoError(t, err)
+
+		output := new(bytes.Buffer)
+
+		// ACT
+		err = extractor.Extract(strings.NewReader(blockContents), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, output.String(), tt.name)
+	}
+}
+
+func TestNewLineBlockExtractor_SJIS(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewLineBlockExtractor(regexp.MustCompile("^開始"), regexp.MustCompile("^終了"), true, false, NewlineLF, "sjis")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(bytes.NewReader(test.StringToByte(t, "あ\n開始\nい\nう\n終了\nえ", japanese.ShiftJIS)), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "い\nう\n", test.ByteToString(t, output.Bytes(), japanese.ShiftJIS))
+}
+
+func TestNewLineBlockExtractor_NoRegex(t *testing.T) {
+
+	// ACT
+	_, err := NewLineBlockExtractor(nil, nil, false, false, NewlineLF, "UTF-8")
+
+	// ASSERT
+	assert.EqualError(t, err, "from or to must be specified")
+}
+
+func TestNewLineBlockExtractor_InvalidEncoding(t *testing.T) {
+
+	// ACT
+	_, err := NewLineBlockExtractor(regexp.MustCompile("a"), nil, false, false, NewlineLF, "xxx")
+
+	// ASSERT
+	require.Error(t, err)
+}
+
+func TestNewLineBlockExtractor_NewlineCR(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewLineBlockExtractor(regexp.MustCompile("^BEGIN$"), regexp.MustCompile("^END$"), true, false, NewlineCR, "UTF-8")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("a\rBEGIN\rb\rc\rEND\rd\r"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "b\rc\r", output.String())
+}
@@ -1,13 +1,17 @@
 package extractor
 
 import (
-	"os"
+	"bytes"
+	"io"
+	"math"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/onozaty/filep/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/htmlindex"
 	"golang.org/x/text/encoding/japanese"
 )
 
@@ -23,8 +27,8 @@ func TestNewLineExtractor(t *testing.T) {
 		output := filepath.Join(d, "output1-10")
 
 		// ACT
-		extractor, _ := NewLineExtractor(1, 10, "UTF-8")
-		err := extractor.Extract(input, output)
+		extractor, _ := NewLineExtractor(1, 10, NewlineLF, "UTF-8")
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -37,8 +41,8 @@ func TestNewLineExtractor(t *testing.T) {
 		output := filepath.Join(d, "output2-9")
 
 		// ACT
-		extractor, _ := NewLineExtractor(2, 9, "UTF-8")
-		err := extractor.Extract(input, output)
+		extractor, _ := NewLineExtractor(2, 9, NewlineLF, "UTF-8")
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -51,8 +55,8 @@ func TestNewLineExtractor(t *testing.T) {
 		output := filepath.Join(d, "output1-11")
 
 		// ACT
-		extractor, _ := NewLineExtractor(1, 11, "UTF-8")
-		err := extractor.Extract(input, output)
+		extractor, _ := NewLineExtractor(1, 11, NewlineLF, "UTF-8")
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -65,8 +69,8 @@ func TestNewLineExtractor(t *testing.T) {
 		output := filepath.Join(d, "output11-12")
 
 		// ACT
-		extractor, _ := NewLineExtractor(11, 12, "UTF-8")
-		err := extractor.Extract(input, output)
+		extractor, _ := NewLineExtractor(11, 12, NewlineLF, "UTF-8")
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -89,8 +93,8 @@ func TestNewLineExtractor_改行無しで終了(t *testing.T) {
 		output := filepath.Join(d, "output1-4")
 
 		// ACT
-		extractor, _ := NewLineExtractor(1, 4, "UTF-8")
-		err := extractor.Extract(input, output)
+		extractor, _ := NewLineExtractor(1, 4, NewlineLF, "UTF-8")
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -103,8 +107,8 @@ func TestNewLineExtractor_改行無しで終了(t *testing.T) {
 		output := filepath.Join(d, "output2-3")
 
 		// ACT
-		extractor, _ := NewLineExtractor(2, 3, "UTF-8")
-		err := extractor.Extract(input, output)
+		extractor, _ := NewLineExtractor(2, 3, NewlineLF, "UTF-8")
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -117,8 +121,8 @@ func TestNewLineExtractor_改行無しで終了(t *testing.T) {
 		output := filepath.Join(d, "output2-2")
 
 		// ACT
-		extractor, _ := NewLineExtractor(2, 2, "utf-8")
-		err := extractor.Extract(input, output)
+		extractor, _ := NewLineExtractor(2, 2, NewlineLF, "utf-8")
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -131,8 +135,8 @@ func TestNewLineExtractor_改行無しで終了(t *testing.T) {
 		output := filepath.Join(d, "output1-1")
 
 		// ACT
-		extractor, _ := NewLineExtractor(1, 1, "UTF-8")
-		err := extractor.Extract(input, output)
+		extractor, _ := NewLineExtractor(1, 1, NewlineLF, "UTF-8")
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -145,8 +149,8 @@ func TestNewLineExtractor_改行無しで終了(t *testing.T) {
 		output := filepath.Join(d, "output12-12")
 
 		// ACT
-		extractor, _ := NewLineExtractor(12, 12, "UTF-8")
-		err := extractor.Extract(input, output)
+		extractor, _ := NewLineExtractor(12, 12, NewlineLF, "UTF-8")
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -169,8 +173,8 @@ func TestNewLineExtractor_SJIS(t *testing.T) {
 		output := filepath.Join(d, "output1-3")
 
 		// ACT
-		extractor, _ := NewLineExtractor(1, 3, "SJIS")
-		err := extractor.Extract(input, output)
+		extractor, _ := NewLineExtractor(1, 3, NewlineLF, "SJIS")
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -183,8 +187,8 @@ func TestNewLineExtractor_SJIS(t *testing.T) {
 		output := filepath.Join(d, "output1-2")
 
 		// ACT
-		extractor, _ := NewLineExtractor(1, 2, "SJIS")
-		err := extractor.Extract(input, output)
+		extractor, _ := NewLineExtractor(1, 2, NewlineLF, "SJIS")
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -197,8 +201,8 @@ func TestNewLineExtractor_SJIS(t *testing.T) {
 		output := filepath.Join(d, "output1")
 
 		// ACT
-		extractor, _ := NewLineExtractor(1, 1, "sjis")
-		err := extractor.Extract(input, output)
+		extractor, _ := NewLineExtractor(1, 1, NewlineLF, "sjis")
+		err := extractor.Extract(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -209,68 +213,216 @@ func TestNewLineExtractor_SJIS(t *testing.T) {
 	}
 }
 
-func TestNewLineExtractor_InputFileNotFound(t *testing.T) {
-
-	// ARRANGE
-	d := t.TempDir()
-
-	input := filepath.Join(d, "xxxx")
-	output := filepath.Join(d, "output")
+func TestNewLineExtractor_InvalidEncoding(t *testing.T) {
 
 	// ACT
-	extractor, _ := NewLineExtractor(1, 9, "UTF-8")
-	err := extractor.Extract(input, output)
+	_, err := NewLineExtractor(1, 9, NewlineLF, "utf")
 
 	// ASSERT
 	require.Error(t, err)
-	pathErr := err.(*os.PathError)
-	assert.Equal(t, input, pathErr.Path)
-	assert.Equal(t, "open", pathErr.Op)
+	assert.EqualError(t, err, "utf is invalid: htmlindex: invalid encoding name")
 }
 
-func TestNewLineExtractor_OutputFileNotFound(t *testing.T) {
+func TestNewLineExtractor_InvalidRange_Start(t *testing.T) {
 
-	// ARRANGE
-	d := t.TempDir()
+	// ACT
+	_, err := NewLineExtractor(0, 10, NewlineLF, "utf-8")
+
+	// ASSERT
+	assert.EqualError(t, err, "invalid range: start = 0, end = 10")
+}
 
-	input := test.CreateFileWriteString(t, d, "input", "")
-	output := filepath.Join(d, "non", "output")
+func TestNewLineExtractor_InvalidRange_End(t *testing.T) {
 
 	// ACT
-	extractor, _ := NewLineExtractor(1, 9, "UTF-8")
-	err := extractor.Extract(input, output)
+	_, err := NewLineExtractor(10, 9, NewlineLF, "utf-8")
 
 	// ASSERT
-	require.Error(t, err)
-	pathErr := err.(*os.PathError)
-	assert.Equal(t, output, pathErr.Path)
-	assert.Equal(t, "open", pathErr.Op)
+	assert.EqualError(t, err, "invalid range: start = 10, end = 9")
 }
 
-func TestNewLineExtractor_InvalidEncoding(t *testing.T) {
+func TestNewLineExtractor_FromEnd(t *testing.T) {
+
+	contents := "1\n2\r\n3\n4\n\n6\r\n7xxxx\n8\n9\n10\n"
+
+	tests := []struct {
+		start    int64
+		end      int64
+		expected string
+	}{
+		{start: -1, end: math.MaxInt64, expected: "10\n"},
+		{start: -3, end: math.MaxInt64, expected: "8\n9\n10\n"},
+		{start: -3, end: -2, expected: "8\n9\n"},
+		{start: 2, end: -8, expected: "2\r\n3\n"},
+		{start: -100, end: 2, expected: "1\n2\r\n"},
+		{start: -11, end: -10, expected: "1\n"},
+		{start: 5, end: -6, expected: "\n"},
+		{start: -2, end: 5, expected: ""},
+		{start: -20, end: -11, expected: ""},
+	}
+
+	// UTF-16とISO-2022-JPは末尾から探せないので、先頭から読み込む
+	for _, encoding := range []string{"UTF-8", "sjis", "utf-16le", "iso-2022-jp"} {
+		enc, err := htmlindex.Get(encoding)
+		require.NoError(t, err)
+		input := test.StringToByte(t, contents, enc)
+
+		for _, tt := range tests {
+			extractor, err := NewLineExtractor(tt.start, tt.end, NewlineLF, encoding)
+			require.NoError(t, err)
+
+			// シーク可能な場合
+			{
+				output := new(bytes.Buffer)
+				err := extractor.Extract(bytes.NewReader(input), output)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, test.ByteToString(t, output.Bytes(), enc), "encoding=%s, start=%d, end=%d", encoding, tt.start, tt.end)
+			}
+			// シークできない場合
+			{
+				output := new(bytes.Buffer)
+				err := extractor.Extract(io.MultiReader(bytes.NewReader(input)), output)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, test.ByteToString(t, output.Bytes(), enc), "encoding=%s, start=%d, end=%d", encoding, tt.start, tt.end)
+			}
+		}
+	}
+}
+
+func TestNewLineExtractor_FromEnd_改行無しで終了(t *testing.T) {
+
+	tests := []struct {
+		start    int64
+		end      int64
+		expected string
+	}{
+		{start: -1, end: math.MaxInt64, expected: "う"},
+		{start: -2, end: -2, expected: "い\n"},
+		{start: 1, end: -3, expected: "あ\n"},
+	}
+
+	for _, tt := range tests {
+		extractor, err := NewLineExtractor(tt.start, tt.end, NewlineLF, "UTF-8")
+		require.NoError(t, err)
+
+		output := new(bytes.Buffer)
+		err = extractor.Extract(strings.NewReader("あ\nい\nう"), output)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, output.String(), "start=%d, end=%d", tt.start, tt.end)
+	}
+}
+
+func TestNewLineExtractor_FromEnd_Empty(t *testing.T) {
+
+	extractor, err := NewLineExtractor(-1, -1, NewlineLF, "UTF-8")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+	err = extractor.Extract(strings.NewReader(""), output)
+	require.NoError(t, err)
+	assert.Equal(t, "", output.String())
+}
+
+func TestNewLineExtractor_FromEnd_Large(t *testing.T) {
+
+	// 読み込み単位をまたぐ場合
+	contents := strings.Repeat(strings.Repeat("x", 99)+"\n", scanBufferSize/50)
+
+	extractor, err := NewLineExtractor(-(scanBufferSize/100 + 1), -(scanBufferSize/100 - 1), NewlineLF, "UTF-8")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+	err = extractor.Extract(strings.NewReader(contents), output)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat(strings.Repeat("x", 99)+"\n", 3), output.String())
+}
+
+func TestNewLineRangesExtractor(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewLineRangesExtractor([]Range{{Start: 1, End: 2}, {Start: 4, End: 4}, {Start: 6, End: math.MaxInt64}}, NewlineLF, "UTF-8")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
 
 	// ACT
-	_, err := NewLineExtractor(1, 9, "utf")
+	err = extractor.Extract(strings.NewReader("1\n2\r\n3\n4\n5\n6\n7"), output)
 
 	// ASSERT
-	require.Error(t, err)
-	assert.EqualError(t, err, "utf is invalid: htmlindex: invalid encoding name")
+	require.NoError(t, err)
+	assert.Equal(t, "1\n2\r\n4\n6\n7", output.String())
 }
 
-func TestNewLineExtractor_InvalidRange_Start(t *testing.T) {
+func TestNewLineRangesExtractor_Overlap(t *testing.T) {
 
 	// ACT
-	_, err := NewLineExtractor(0, 10, "utf-8")
+	_, err := NewLineRangesExtractor([]Range{{Start: 3, End: 4}, {Start: 1, End: 2}}, NewlineLF, "UTF-8")
 
 	// ASSERT
-	assert.EqualError(t, err, "invalid range: start = 0, end = 10")
+	assert.EqualError(t, err, "ranges must be in ascending order without overlap: 3-4, 1-2")
 }
 
-func TestNewLineExtractor_InvalidRange_End(t *testing.T) {
+func TestNewLineExtractor_Newline(t *testing.T) {
+
+	contents := "1\r2\r\n3\n4\r5"
+
+	tests := []struct {
+		name     string
+		newline  Newline
+		start    int64
+		end      int64
+		expected string
+	}{
+		{name: "cr", newline: NewlineCR, start: 2, end: 3, expected: "2\r\n3\n4\r"},
+		{name: "crlf", newline: NewlineCRLF, start: 2, end: 2, expected: "3\n4\r5"},
+		{name: "any", newline: NewlineAny, start: 2, end: 4, expected: "2\r\n3\n4\r"},
+		{name: "any", newline: NewlineAny, start: -2, end: -1, expected: "4\r5"},
+		{name: "cr", newline: NewlineCR, start: -2, end: -2, expected: "\n3\n4\r"},
+		{name: "cr", newline: NewlineCR, start: 1, end: -3, expected: "1\r2\r"},
+	}
+
+	for _, encoding := range []string{"UTF-8", "sjis", "utf-16le"} {
+		enc, err := htmlindex.Get(encoding)
+		require.NoError(t, err)
+		input := test.StringToByte(t, contents, enc)
+
+		for _, tt := range tests {
+			extractor, err := NewLineExtractor(tt.start, tt.end, tt.newline, encoding)
+			require.NoError(t, err)
+
+			// シーク可能な場合
+			{
+				output := new(bytes.Buffer)
+				err := extractor.Extract(bytes.NewReader(input), output)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, test.ByteToString(t, output.Bytes(), enc), "encoding=%s, newline=%s, start=%d, end=%d", encoding, tt.name, tt.start, tt.end)
+			}
+			// シークできない場合
+			{
+				output := new(bytes.Buffer)
+				err := extractor.Extract(io.MultiReader(bytes.NewReader(input)), output)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, test.ByteToString(t, output.Bytes(), enc), "encoding=%s, newline=%s, start=%d, end=%d", encoding, tt.name, tt.start, tt.end)
+			}
+		}
+	}
+}
+
+func TestNewLineRangesExtractor_CustomNewline(t *testing.T) {
+
+	// ARRANGE
+	newline, err := NewCustomNewline("\x00")
+	require.NoError(t, err)
+
+	extractor, err := NewLineRangesExtractor([]Range{{Start: 1, End: 1}, {Start: 3, End: 3}}, newline, "UTF-8")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
 
 	// ACT
-	_, err := NewLineExtractor(10, 9, "utf-8")
+	err = extractor.Extract(strings.NewReader("a\nb\x00c\x00d e\x00"), output)
 
 	// ASSERT
-	assert.EqualError(t, err, "invalid range: start = 10, end = 9")
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb\x00d e\x00", output.String())
 }
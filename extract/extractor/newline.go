@@ -0,0 +1,306 @@
+package extractor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// 行の区切りとする改行です。
+type Newline struct {
+	// いずれかに一致した箇所を行の区切りとする(長いものから順に判定)
+	terminators []string
+}
+
+var (
+	NewlineLF   = Newline{terminators: []string{"\n"}}
+	NewlineCR   = Newline{terminators: []string{"\r"}}
+	NewlineCRLF = Newline{terminators: []string{"\r\n"}}
+	// CRLF、LF、CRのいずれも改行とする
+	NewlineAny = Newline{terminators: []string{"\r\n", "\n", "\r"}}
+	// Unicodeで改行とされるもの(CRLF、LF、CRに加えて、VT、FF、NEL、LS、PS)
+	NewlineUnicode = Newline{terminators: []string{"\r\n", "\n", "\v", "\f", "\r", "\u0085", "\u2028", "\u2029"}}
+)
+
+// 任意の文字列(NULなど)を改行とします。
+func NewCustomNewline(terminator string) (Newline, error) {
+
+	if terminator == "" {
+		return Newline{}, fmt.Errorf("newline must not be empty")
+	}
+
+	return Newline{terminators: []string{terminator}}, nil
+}
+
+// 行の末尾の改行を除いた内容と、改行を返します。
+// LFの場合、直前のCRも改行として扱います。
+func (n Newline) cut(line string) (string, string) {
+
+	for _, terminator := range n.terminators {
+		if content, found := strings.CutSuffix(line, terminator); found {
+			if terminator == "\n" && strings.HasSuffix(content, "\r") {
+				content = content[:len(content)-1]
+			}
+			return content, line[len(content):]
+		}
+	}
+
+	// 改行で終わっていない最後の行
+	return line, ""
+}
+
+// 改行が1バイトで表され、他の文字の一部として現れることが無い場合に、そのバイトを返します。
+func (n Newline) singleByte(encoding encoding.Encoding) (byte, bool) {
+
+	if len(n.terminators) != 1 || len(n.terminators[0]) != 1 {
+		return 0, false
+	}
+
+	// マルチバイトの文字の一部として使われるのは0x20以上
+	b := n.terminators[0][0]
+	if b >= 0x20 {
+		return 0, false
+	}
+
+	// ISO-2022-JPはエスケープシーケンスによる状態を持つため、途中から読み込めない
+	if name, _ := htmlindex.Name(encoding); name == "iso-2022-jp" {
+		return 0, false
+	}
+
+	// UTF-16などは他のバイトも含まれる
+	encoded, err := encoding.NewEncoder().Bytes([]byte{b})
+	if err != nil || !bytes.Equal(encoded, []byte{b}) {
+		return 0, false
+	}
+
+	return b, true
+}
+
+// デコードされた文字を順に読み込みながら、改行を判定するためのものです。
+type newlineMatcher struct {
+	reader      *bufio.Reader
+	terminators [][]byte
+	// 直近に読み込んだ内容(改行の長さ分だけ保持し、行の区切りでクリア)
+	tail    []byte
+	tailMax int
+}
+
+func (n Newline) newMatcher(reader *bufio.Reader) *newlineMatcher {
+
+	terminators := [][]byte{}
+	tailMax := 0
+	for _, terminator := range n.terminators {
+		terminators = append(terminators, []byte(terminator))
+		tailMax = max(tailMax, len(terminator))
+	}
+
+	return &newlineMatcher{
+		reader:      reader,
+		terminators: terminators,
+		tailMax:     tailMax,
+	}
+}
+
+// 読み込んだ文字を加え、その文字で行が終わったかを返します。
+func (m *newlineMatcher) push(c rune) bool {
+
+	m.tail = utf8.AppendRune(m.tail, c)
+	if len(m.tail) > m.tailMax {
+		m.tail = append(m.tail[:0], m.tail[len(m.tail)-m.tailMax:]...)
+	}
+
+	for _, terminator := range m.terminators {
+		if !bytes.HasSuffix(m.tail, terminator) {
+			continue
+		}
+		if m.continues(terminator) {
+			// CRLFのCRのように、より長い改行の途中の場合
+			return false
+		}
+		m.tail = m.tail[:0]
+		return true
+	}
+
+	return false
+}
+
+// 一致した改行を先頭部分として含む、より長い改行が続くかを判定します。
+func (m *newlineMatcher) continues(matched []byte) bool {
+
+	for _, terminator := range m.terminators {
+		if len(terminator) <= len(matched) || !bytes.HasPrefix(terminator, matched) {
+			continue
+		}
+
+		rest := terminator[len(matched):]
+		if next, _ := m.reader.Peek(len(rest)); bytes.Equal(next, rest) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 改行を含めて1行を読み込みます。
+// 改行で終わっていない最後の行は、その内容とio.EOFを返します。
+func (m *newlineMatcher) readLine() (string, error) {
+
+	if len(m.terminators) == 1 && len(m.terminators[0]) == 1 {
+		return m.reader.ReadString(m.terminators[0][0])
+	}
+
+	var line strings.Builder
+	for {
+		c, _, err := m.reader.ReadRune()
+		if err != nil {
+			return line.String(), err
+		}
+
+		line.WriteRune(c)
+		if m.push(c) {
+			return line.String(), nil
+		}
+	}
+}
+
+// 改行を除いた行の内容を、1行ずつ順に読み込むためのものです。
+// 行全体を保持せずに、行の内容をio.Readerとして読み込めます。
+type lineReader struct {
+	reader *bufio.Reader
+	// 長いものから順に判定(LFの場合は直前のCRも改行として扱うため、CRLFも含める)
+	terminators []string
+	// 改行の先頭となるバイト
+	starts [256]bool
+	// 内容を読み終わった行の改行(改行で終わっていない最後の行は空)
+	terminator string
+	// 現在の行の内容を読み終わったか
+	end bool
+}
+
+func (n Newline) newLineReader(reader *bufio.Reader) *lineReader {
+
+	terminators := slices.Clone(n.terminators)
+	if slices.Contains(terminators, "\n") && !slices.Contains(terminators, "\r\n") {
+		terminators = append(terminators, "\r\n")
+	}
+	slices.SortStableFunc(terminators, func(a string, b string) int {
+		return len(b) - len(a)
+	})
+
+	l := &lineReader{
+		reader:      reader,
+		terminators: terminators,
+		end:         true,
+	}
+	for _, terminator := range terminators {
+		l.starts[terminator[0]] = true
+	}
+
+	return l
+}
+
+// 次の行に進みます。入力の終わりに達している場合はfalseを返します。
+func (l *lineReader) next() (bool, error) {
+
+	if err := l.skip(); err != nil {
+		return false, err
+	}
+
+	if _, err := l.reader.Peek(1); err == io.EOF {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	l.end = false
+	l.terminator = ""
+	return true, nil
+}
+
+// 現在の行の内容を読み込みます。改行に達した場合はio.EOFを返します。
+func (l *lineReader) Read(p []byte) (int, error) {
+
+	if len(p) < utf8.UTFMax {
+		return 0, io.ErrShortBuffer
+	}
+
+	n := 0
+	for !l.end && n+utf8.UTFMax <= len(p) {
+		// 改行の先頭になり得ないバイトは、バッファからまとめて読み込む
+		if copied := l.copyContent(p[n:]); copied > 0 {
+			n += copied
+			continue
+		}
+
+		matched, err := l.readTerminator()
+		if err != nil {
+			return n, err
+		}
+		if matched {
+			l.end = true
+			break
+		}
+
+		c, _, err := l.reader.ReadRune()
+		if err == io.EOF {
+			l.end = true
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+		n += utf8.EncodeRune(p[n:], c)
+	}
+
+	if n == 0 && l.end {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// バッファにある内容のうち、改行の先頭となるバイトより前までをpにコピーし、コピーした大きさを返します。
+func (l *lineReader) copyContent(p []byte) int {
+
+	buffered, _ := l.reader.Peek(min(l.reader.Buffered(), len(p)))
+
+	size := 0
+	for size < len(buffered) && !l.starts[buffered[size]] {
+		size++
+	}
+
+	copy(p, buffered[:size])
+	l.reader.Discard(size)
+	return size
+}
+
+// 現在の行の内容の残りを読み捨てます。
+func (l *lineReader) skip() error {
+
+	_, err := io.Copy(io.Discard, l)
+	return err
+}
+
+// 改行が続く場合は、それを読み込んでterminatorに保持します。
+func (l *lineReader) readTerminator() (bool, error) {
+
+	for _, terminator := range l.terminators {
+		next, err := l.reader.Peek(len(terminator))
+		if err != nil && err != io.EOF {
+			return false, err
+		}
+		if string(next) == terminator {
+			l.terminator = terminator
+			_, err := l.reader.Discard(len(terminator))
+			return true, err
+		}
+	}
+
+	return false, nil
+}
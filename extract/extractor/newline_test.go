@@ -0,0 +1,217 @@
+package extractor
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestNewline_ReadLine(t *testing.T) {
+
+	input := "1\n2\r\n3\r4\u20285\u0085\x006\r"
+
+	tests := []struct {
+		name     string
+		newline  Newline
+		expected []string
+	}{
+		{name: "lf", newline: NewlineLF, expected: []string{"1\n", "2\r\n", "3\r4\u20285\u0085\x006\r"}},
+		{name: "cr", newline: NewlineCR, expected: []string{"1\n2\r", "\n3\r", "4\u20285\u0085\x006\r"}},
+		{name: "crlf", newline: NewlineCRLF, expected: []string{"1\n2\r\n", "3\r4\u20285\u0085\x006\r"}},
+		{name: "any", newline: NewlineAny, expected: []string{"1\n", "2\r\n", "3\r", "4\u20285\u0085\x006\r"}},
+		{name: "unicode", newline: NewlineUnicode, expected: []string{"1\n", "2\r\n", "3\r", "4\u2028", "5\u0085", "\x006\r"}},
+		{name: "custom", newline: mustCustomNewline(t, "\x00"), expected: []string{"1\n2\r\n3\r4\u20285\u0085\x00", "6\r"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// ARRANGE
+			matcher := tt.newline.newMatcher(bufio.NewReader(strings.NewReader(input)))
+
+			// ACT
+			lines := []string{}
+			for {
+				line, err := matcher.readLine()
+				if line != "" {
+					lines = append(lines, line)
+				}
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+			}
+
+			// ASSERT
+			assert.Equal(t, tt.expected, lines)
+		})
+	}
+}
+
+func TestNewline_ReadLine_MultiChar(t *testing.T) {
+
+	// ARRANGE
+	matcher := mustCustomNewline(t, "--").newMatcher(bufio.NewReader(strings.NewReader("a---b--")))
+
+	// ACT
+	line1, err1 := matcher.readLine()
+	line2, err2 := matcher.readLine()
+	line3, err3 := matcher.readLine()
+
+	// ASSERT
+	// 行の区切りで判定はリセットされる
+	assert.Equal(t, "a--", line1)
+	require.NoError(t, err1)
+	assert.Equal(t, "-b--", line2)
+	require.NoError(t, err2)
+	assert.Equal(t, "", line3)
+	assert.Equal(t, io.EOF, err3)
+}
+
+func TestNewline_LineReader(t *testing.T) {
+
+	input := "1\n2\r\n3\r4--5"
+
+	tests := []struct {
+		name     string
+		newline  Newline
+		expected []string
+	}{
+		// LFの場合、直前のCRも改行として扱う
+		{name: "lf", newline: NewlineLF, expected: []string{"1", "\n", "2", "\r\n", "3\r4--5", ""}},
+		{name: "cr", newline: NewlineCR, expected: []string{"1\n2", "\r", "\n3", "\r", "4--5", ""}},
+		{name: "any", newline: NewlineAny, expected: []string{"1", "\n", "2", "\r\n", "3", "\r", "4--5", ""}},
+		{name: "custom", newline: mustCustomNewline(t, "--"), expected: []string{"1\n2\r\n3\r4", "--", "5", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// ARRANGE
+			lines := tt.newline.newLineReader(bufio.NewReader(strings.NewReader(input)))
+
+			// ACT
+			results := []string{}
+			for {
+				exists, err := lines.next()
+				require.NoError(t, err)
+				if !exists {
+					break
+				}
+
+				content, err := io.ReadAll(lines)
+				require.NoError(t, err)
+				results = append(results, string(content), lines.terminator)
+			}
+
+			// ASSERT
+			assert.Equal(t, tt.expected, results)
+		})
+	}
+}
+
+func TestNewline_LineReader_Skip(t *testing.T) {
+
+	// ARRANGE
+	lines := NewlineLF.newLineReader(bufio.NewReader(strings.NewReader("abcdef\nxyz")))
+
+	// ACT
+	exists1, err1 := lines.next()
+	head := make([]byte, utf8.UTFMax)
+	n, _ := lines.Read(head)
+	// 残りを読まずに次の行へ進む
+	exists2, err2 := lines.next()
+	content, err3 := io.ReadAll(lines)
+	exists3, err4 := lines.next()
+
+	// ASSERT
+	assert.True(t, exists1)
+	require.NoError(t, err1)
+	assert.Equal(t, "abcd", string(head[:n]))
+	assert.True(t, exists2)
+	require.NoError(t, err2)
+	assert.Equal(t, "xyz", string(content))
+	require.NoError(t, err3)
+	assert.False(t, exists3)
+	require.NoError(t, err4)
+}
+
+func TestNewline_Cut(t *testing.T) {
+
+	tests := []struct {
+		newline    Newline
+		line       string
+		content    string
+		terminator string
+	}{
+		{newline: NewlineLF, line: "abc\n", content: "abc", terminator: "\n"},
+		{newline: NewlineLF, line: "abc\r\n", content: "abc", terminator: "\r\n"},
+		{newline: NewlineLF, line: "abc", content: "abc", terminator: ""},
+		{newline: NewlineCR, line: "abc\r", content: "abc", terminator: "\r"},
+		{newline: NewlineAny, line: "abc\r\n", content: "abc", terminator: "\r\n"},
+		{newline: NewlineUnicode, line: "abc\u2029", content: "abc", terminator: "\u2029"},
+	}
+
+	for _, tt := range tests {
+		// ACT
+		content, terminator := tt.newline.cut(tt.line)
+
+		// ASSERT
+		assert.Equal(t, tt.content, content)
+		assert.Equal(t, tt.terminator, terminator)
+	}
+}
+
+func TestNewline_SingleByte(t *testing.T) {
+
+	{
+		b, ok := NewlineCR.singleByte(japanese.ShiftJIS)
+		assert.True(t, ok)
+		assert.Equal(t, byte('\r'), b)
+	}
+	{
+		b, ok := mustCustomNewline(t, "\x00").singleByte(unicode.UTF8)
+		assert.True(t, ok)
+		assert.Equal(t, byte(0), b)
+	}
+	{
+		// 他の文字の一部として現れ得るもの
+		_, ok := mustCustomNewline(t, ",").singleByte(unicode.UTF8)
+		assert.False(t, ok)
+	}
+	{
+		_, ok := NewlineAny.singleByte(unicode.UTF8)
+		assert.False(t, ok)
+	}
+	{
+		_, ok := NewlineLF.singleByte(unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM))
+		assert.False(t, ok)
+	}
+	{
+		_, ok := NewlineLF.singleByte(japanese.ISO2022JP)
+		assert.False(t, ok)
+	}
+}
+
+func TestNewCustomNewline_Empty(t *testing.T) {
+
+	// ACT
+	_, err := NewCustomNewline("")
+
+	// ASSERT
+	assert.EqualError(t, err, "newline must not be empty")
+}
+
+func mustCustomNewline(t *testing.T, terminator string) Newline {
+
+	newline, err := NewCustomNewline(terminator)
+	require.NoError(t, err)
+	return newline
+}
@@ -0,0 +1,199 @@
+package extractor
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	enc "github.com/onozaty/filep/encoding"
+
+	"golang.org/x/text/transform"
+)
+
+type perLineExtractor struct {
+	num int64
+	// 切り捨てた行の末尾に付ける文字列
+	suffix string
+	// suffixを含めて指定された大きさに収める場合に、suffixのために空けておく大きさ
+	reserve int64
+	newline Newline
+	// 取り出した先頭部分やsuffixの大きさを数えるためのもの
+	units SizedUnits
+	// 行の内容を先頭から順に読み込むためのもの
+	contentUnits SizedUnits
+}
+
+// NewPerLineByteExtractor は、各行を先頭から指定されたバイト数までに切り詰めるExtractorを作成します。
+// バイト数はエンコーディングでの長さで、文字の途中では切りません。
+func NewPerLineByteExtractor(byteNum int64, suffix string, suffixInLimit bool, newline Newline, encodingName string) (Extractor, error) {
+
+	encoding, err := enc.Encoding(encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPerLineExtractor(byteNum, suffix, suffixInLimit, newline, byteUnits(encoding), charByteUnits(encoding))
+}
+
+// NewPerLineCharExtractor は、各行を先頭から指定された文字数までに切り詰めるExtractorを作成します。
+func NewPerLineCharExtractor(charNum int64, unit CharUnit, suffix string, suffixInLimit bool, newline Newline, encodingName string) (Extractor, error) {
+
+	encoding, err := enc.Encoding(encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	units := charUnits(unit, encoding)
+	return newPerLineExtractor(charNum, suffix, suffixInLimit, newline, units, units)
+}
+
+// NewPerLineWidthExtractor は、各行を先頭から指定された表示幅までに切り詰めるExtractorを作成します。
+// 幅が0の文字は、直前の文字に含めます。
+func NewPerLineWidthExtractor(width int64, ambiguousWidth int, suffix string, suffixInLimit bool, newline Newline, encodingName string) (Extractor, error) {
+
+	if err := validateAmbiguousWidth(ambiguousWidth); err != nil {
+		return nil, err
+	}
+
+	encoding, err := enc.Encoding(encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	units := widthUnits(int64(ambiguousWidth), encoding)
+	return newPerLineExtractor(width, suffix, suffixInLimit, newline, units, units)
+}
+
+func newPerLineExtractor(num int64, suffix string, suffixInLimit bool, newline Newline, units SizedUnits, contentUnits SizedUnits) (Extractor, error) {
+
+	if num < 0 {
+		return nil, fmt.Errorf("number must be greater than or equal to 0")
+	}
+
+	reserve, err := suffixReserve(suffix, suffixInLimit, num, units)
+	if err != nil {
+		return nil, err
+	}
+
+	return &perLineExtractor{
+		num:          num,
+		suffix:       suffix,
+		reserve:      reserve,
+		newline:      newline,
+		units:        units,
+		contentUnits: contentUnits,
+	}, nil
+}
+
+func (t *perLineExtractor) Extract(input io.Reader, output io.Writer) error {
+
+	encoding := t.units.encoding
+	reader := bufio.NewReader(transform.NewReader(input, encoding.NewDecoder()))
+	writer := bufio.NewWriter(transform.NewWriter(output, encoding.NewEncoder()))
+	lines := t.newline.newLineReader(reader)
+
+	for {
+		exists, err := lines.next()
+		if err != nil {
+			return err
+		}
+		if !exists {
+			break
+		}
+
+		head, truncated, err := t.head(lines)
+		if err != nil {
+			return err
+		}
+		if truncated {
+			head += t.suffix
+		}
+
+		// 行全体を保持しないように、指定された大きさを超えた残りは読み捨てる
+		if err := lines.skip(); err != nil {
+			return err
+		}
+
+		// 改行は元のまま残す
+		if _, err := writer.WriteString(head + lines.terminator); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+// 行の内容を単位ごとに読み込み、指定された大きさに収まる先頭部分と、切り捨てたかどうかを返します。
+// 指定された大きさを超えた時点で読み込みを止めるため、行の残りは読み込まずに残ります。
+func (t *perLineExtractor) head(content io.Reader) (string, bool, error) {
+
+	next := t.contentUnits.newReader(content)
+
+	// 指定された大きさまでの単位と、それぞれの終わりの位置と大きさの合計
+	var head strings.Builder
+	ends := []int{}
+	totals := []int64{}
+	var total int64
+	truncated := false
+	for {
+		value, size, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", false, err
+		}
+
+		total += size
+		if total > t.num {
+			truncated = true
+			break
+		}
+		head.WriteString(value)
+		ends = append(ends, head.Len())
+		totals = append(totals, total)
+	}
+
+	limit := t.num
+	if truncated {
+		// suffixの分を空ける
+		limit = t.num - t.reserve
+	}
+
+	count := len(ends)
+	for {
+		for count > 0 && totals[count-1] > limit {
+			count--
+		}
+
+		candidate := head.String()[:endOf(ends, count)]
+
+		// ISO-2022-JPのように末尾で状態を戻すエスケープシーケンスが付くものもあるため、
+		// 全体の大きさで収まるかを確認し、収まらなければ単位を減らす
+		size, err := t.units.size(candidate)
+		if err != nil {
+			return "", false, err
+		}
+		if size <= limit {
+			return candidate, truncated, nil
+		}
+
+		if !truncated {
+			truncated = true
+			limit = t.num - t.reserve
+			continue
+		}
+		count--
+	}
+}
+
+// 先頭からcount個の単位の終わりの位置を返します。
+func endOf(ends []int, count int) int {
+
+	if count == 0 {
+		return 0
+	}
+
+	return ends[count-1]
+}
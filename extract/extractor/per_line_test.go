@@ -0,0 +1,281 @@
+package extractor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestNewPerLineCharExtractor(t *testing.T) {
+
+	tests := []struct {
+		charNum  int64
+		suffix   string
+		expected string
+	}{
+		{charNum: 3, suffix: "", expected: "abc\r\nあいう\n\nxy\n123"},
+		{charNum: 3, suffix: "…", expected: "abc…\r\nあいう…\n\nxy\n123…"},
+		{charNum: 5, suffix: "…", expected: "abcde\r\nあいうえ\n\nxy\n12345"},
+		{charNum: 0, suffix: "…", expected: "…\r\n…\n\n…\n…"},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		extractor, err := NewPerLineCharExtractor(tt.charNum, CharUnitRune, tt.suffix, false, NewlineLF, "UTF-8")
+		require.NoError(t, err)
+
+		output := new(bytes.Buffer)
+
+		// ACT
+		err = extractor.Extract(strings.NewReader("abcde\r\nあいうえ\n\nxy\n12345"), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, output.String(), "charNum=%d, suffix=%s", tt.charNum, tt.suffix)
+	}
+}
+
+func TestNewPerLineCharExtractor_Grapheme(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewPerLineCharExtractor(2, CharUnitGrapheme, "", false, NewlineLF, "UTF-8")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("か\u3099き\u3099く\nけ\u3099"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "か\u3099き\u3099\nけ\u3099", output.String())
+}
+
+func TestNewPerLineCharExtractor_Newline(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewPerLineCharExtractor(2, CharUnitRune, "~", false, NewlineCR, "UTF-8")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("abc\rde\rf\ng\r"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "ab~\rde\rf\n~\r", output.String())
+}
+
+func TestNewPerLineByteExtractor(t *testing.T) {
+
+	tests := []struct {
+		byteNum  int64
+		expected string
+	}{
+		{byteNum: 3, expected: "abc…\nあ…\n12\n"},
+		{byteNum: 5, expected: "abcde\nあ…\n12\n"},
+		{byteNum: 6, expected: "abcde\nあい…\n12\n"},
+		{byteNum: 9, expected: "abcde\nあいう\n12\n"},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		extractor, err := NewPerLineByteExtractor(tt.byteNum, "…", false, NewlineLF, "UTF-8")
+		require.NoError(t, err)
+
+		output := new(bytes.Buffer)
+
+		// ACT
+		err = extractor.Extract(strings.NewReader("abcde\nあいう\n12\n"), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, output.String(), "byteNum=%d", tt.byteNum)
+	}
+}
+
+func TestNewPerLineByteExtractor_SJIS(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewPerLineByteExtractor(5, "", false, NewlineLF, "sjis")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(bytes.NewReader(test.StringToByte(t, "あいう\nｱｲｳｴｵｶ\n", japanese.ShiftJIS)), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "あい\nｱｲｳｴｵ\n", test.ByteToString(t, output.Bytes(), japanese.ShiftJIS))
+}
+
+func TestNewPerLineByteExtractor_UTF16(t *testing.T) {
+
+	// ARRANGE
+	enc := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	extractor, err := NewPerLineByteExtractor(4, "", false, NewlineLF, "utf-16le")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(bytes.NewReader(test.StringToByte(t, "abc\n😀a\n", enc)), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "ab\n😀\n", test.ByteToString(t, output.Bytes(), enc))
+}
+
+func TestNewPerLineByteExtractor_ISO2022JP(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewPerLineByteExtractor(8, "", false, NewlineLF, "iso-2022-jp")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(bytes.NewReader(test.StringToByte(t, "あ\nあい\n", japanese.ISO2022JP)), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	// エスケープシーケンスを含めて8バイトに収まるもの
+	assert.Equal(t, "あ\nあ\n", test.ByteToString(t, output.Bytes(), japanese.ISO2022JP))
+}
+
+func TestNewPerLineByteExtractor_ISO2022JP_Suffix(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewPerLineByteExtractor(10, "~", false, NewlineLF, "iso-2022-jp")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(bytes.NewReader(test.StringToByte(t, "あい\nあいa\nあいう\n", japanese.ISO2022JP)), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	// 順に数えると収まっても、末尾のエスケープシーケンスを含めると超える場合は切り捨てる
+	assert.Equal(t, "あい\nあい~\nあい~\n", test.ByteToString(t, output.Bytes(), japanese.ISO2022JP))
+}
+
+func TestNewPerLineCharExtractor_LongLine(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewPerLineCharExtractor(3, CharUnitRune, "…", false, NewlineCRLF, "UTF-8")
+	require.NoError(t, err)
+
+	// バッファの大きさを超える行
+	long := strings.Repeat("あ\r", 100*1024)
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("abc\r\n"+long+"\r\nxyz"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "abc\r\nあ\rあ…\r\nxyz", output.String())
+}
+
+func TestNewPerLineWidthExtractor(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewPerLineWidthExtractor(5, 1, "...", false, NewlineAny, "UTF-8")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("abあいう\r\nabcde\rあいうえお"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "abあ...\r\nabcde\rあい...", output.String())
+}
+
+func TestNewPerLineExtractor_Invalid(t *testing.T) {
+
+	{
+		// ACT
+		_, err := NewPerLineCharExtractor(-1, CharUnitRune, "", false, NewlineLF, "UTF-8")
+
+		// ASSERT
+		assert.EqualError(t, err, "number must be greater than or equal to 0")
+	}
+	{
+		// ACT
+		_, err := NewPerLineWidthExtractor(1, 3, "", false, NewlineLF, "UTF-8")
+
+		// ASSERT
+		assert.EqualError(t, err, "ambiguous width must be 1 or 2")
+	}
+	{
+		// ACT
+		_, err := NewPerLineByteExtractor(1, "", false, NewlineLF, "utf")
+
+		// ASSERT
+		assert.EqualError(t, err, "utf is invalid: htmlindex: invalid encoding name")
+	}
+}
+
+func TestNewPerLineExtractor_SuffixInLimit(t *testing.T) {
+
+	{
+		// ARRANGE
+		extractor, err := NewPerLineCharExtractor(4, CharUnitRune, "..", true, NewlineLF, "UTF-8")
+		require.NoError(t, err)
+
+		output := new(bytes.Buffer)
+
+		// ACT
+		err = extractor.Extract(strings.NewReader("abcde\nabcd\n"), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "ab..\nabcd\n", output.String())
+	}
+	{
+		// ARRANGE
+		extractor, err := NewPerLineByteExtractor(7, "…", true, NewlineLF, "UTF-8")
+		require.NoError(t, err)
+
+		output := new(bytes.Buffer)
+
+		// ACT
+		err = extractor.Extract(strings.NewReader("あいうえ\nあい\n"), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "あ…\nあい\n", output.String())
+	}
+	{
+		// ARRANGE
+		extractor, err := NewPerLineWidthExtractor(5, 1, "~", true, NewlineLF, "UTF-8")
+		require.NoError(t, err)
+
+		output := new(bytes.Buffer)
+
+		// ACT
+		err = extractor.Extract(strings.NewReader("あいう\nabcde\n"), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "あい~\nabcde\n", output.String())
+	}
+	{
+		// ACT
+		_, err := NewPerLineCharExtractor(1, CharUnitRune, "..", true, NewlineLF, "UTF-8")
+
+		// ASSERT
+		assert.EqualError(t, err, "suffix must not exceed the number")
+	}
+}
@@ -0,0 +1,396 @@
+package extractor
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"unicode/utf8"
+)
+
+// 開始位置と終了位置は1から数えた位置で、負の値の場合は末尾から数えた位置(-1が最後)となります。
+func validateRange(start int64, end int64) error {
+
+	if start == 0 || end == 0 {
+		return fmt.Errorf("invalid range: start = %d, end = %d", start, end)
+	}
+
+	// 符号が異なる場合は、全体の長さが分かるまで前後関係は判断できない
+	if (start > 0) == (end > 0) && end < start {
+		return fmt.Errorf("invalid range: start = %d, end = %d", start, end)
+	}
+
+	return nil
+}
+
+func isFromEnd(start int64, end int64) bool {
+
+	return start < 0 || end < 0
+}
+
+// 末尾からの位置を、全体の数(total)を元に先頭からの位置に変換します。
+func resolvePosition(pos int64, total int64) int64 {
+
+	if pos > 0 {
+		return pos
+	}
+
+	return total + 1 + pos
+}
+
+// 単位(バイト、文字、行)ごとに読み込みながら、末尾からの位置を含む範囲を取り出します。
+// 全体の数が分かるまで、出力するかどうか決まらないものは保持しておきます。
+func extractUnits[T any](next func() (T, error), write func(T) error, start int64, end int64) error {
+
+	type unit struct {
+		index int64
+		value T
+	}
+
+	pending := []unit{}
+	var total int64
+	for {
+		value, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		total++
+
+		if start > 0 && total < start {
+			continue
+		}
+		if end > 0 && total > end {
+			if start > 0 {
+				// これ以降は出力されない
+				break
+			}
+			// 末尾からの開始位置を決めるために、最後まで数える
+			continue
+		}
+
+		pending = append(pending, unit{index: total, value: value})
+
+		if start < 0 && int64(len(pending)) > -start {
+			// 末尾から数えた開始位置より前になったもの
+			pending = pending[1:]
+		}
+		if start > 0 && end < 0 {
+			// 末尾から数えた終了位置より後ろになり得るものだけを保持し、それ以外は出力
+			for int64(len(pending)) > -end-1 {
+				if err := write(pending[0].value); err != nil {
+					return err
+				}
+				pending = pending[1:]
+			}
+		}
+	}
+
+	first := resolvePosition(start, total)
+	last := resolvePosition(end, total)
+	for _, u := range pending {
+		if u.index >= first && u.index <= last {
+			if err := write(u.value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// 大きさ(文字のバイト数や表示幅)を持つ単位ごとに読み込みながら、範囲内に全体が収まる単位を取り出します。
+// 大きさが0の単位は、直前の単位の最後の位置にあるものとして扱います。
+// (先頭にある場合は、最初の位置にあるものとして扱います)
+func extractSizedUnits[T any](next func() (T, int64, error), write func(T) error, ranges []Range) error {
+
+	if len(ranges) == 1 && isFromEnd(ranges[0].Start, ranges[0].End) {
+		return extractSizedUnitsFromEnd(next, write, ranges[0].Start, ranges[0].End)
+	}
+
+	rangeIndex := 0
+	var total int64
+	for rangeIndex < len(ranges) {
+		value, size, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		first := max(min(total+1, total+size), 1)
+		total += size
+
+		// 単位の最後の位置が終了位置を超えたら次の範囲へ
+		for rangeIndex < len(ranges) && ranges[rangeIndex].End < total {
+			rangeIndex++
+		}
+		if rangeIndex < len(ranges) && first >= ranges[rangeIndex].Start {
+			if err := write(value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// 末尾からの位置を含む範囲について、範囲内に全体が収まる単位を取り出します。
+// 全体の大きさが分かるまで、出力するかどうか決まらない単位は保持しておきます。
+func extractSizedUnitsFromEnd[T any](next func() (T, int64, error), write func(T) error, start int64, end int64) error {
+
+	type unit struct {
+		first int64
+		last  int64
+		value T
+	}
+
+	pending := []unit{}
+	var total int64
+	for {
+		value, size, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		first := max(min(total+1, total+size), 1)
+		total += size
+
+		if start > 0 && first < start {
+			continue
+		}
+		if end > 0 && total > end {
+			if start > 0 {
+				// これ以降は出力されない
+				break
+			}
+			// 末尾からの開始位置を決めるために、最後まで数える
+			continue
+		}
+
+		pending = append(pending, unit{first: first, last: total, value: value})
+
+		if start < 0 {
+			// 末尾から数えた開始位置より前になったもの
+			for len(pending) > 0 && pending[0].first < resolvePosition(start, total) {
+				pending = pending[1:]
+			}
+		}
+		if start > 0 && end < 0 {
+			// 末尾から数えた終了位置より前に収まることが確定したものは出力
+			for len(pending) > 0 && pending[0].last <= resolvePosition(end, total) {
+				if err := write(pending[0].value); err != nil {
+					return err
+				}
+				pending = pending[1:]
+			}
+		}
+	}
+
+	first := resolvePosition(start, total)
+	last := resolvePosition(end, total)
+	for _, u := range pending {
+		if u.first >= first && u.last <= last {
+			if err := write(u.value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// シーク可能な場合に、現在位置と、現在位置から末尾までのサイズを返します。
+func seekableSize(input io.Reader) (io.ReadSeeker, int64, int64, bool) {
+
+	seeker, ok := input.(io.ReadSeeker)
+	if !ok {
+		return nil, 0, 0, false
+	}
+
+	// パイプなどはシークに失敗する
+	base, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, 0, 0, false
+	}
+	last, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, 0, 0, false
+	}
+	if _, err := seeker.Seek(base, io.SeekStart); err != nil {
+		return nil, 0, 0, false
+	}
+
+	return seeker, base, last - base, true
+}
+
+// 単位の区切りをバイト単位で判断できる場合に、単位の開始位置(バイト)を前後から探すもの
+type unitScanner struct {
+	input io.ReadSeeker
+	base  int64
+	size  int64
+	// offsetの位置が単位の開始位置か(直前のバイトと、その位置のバイトで判断)
+	isStart func(prev byte, current byte) bool
+}
+
+const scanBufferSize = 64 * 1024
+
+func newLineScanner(input io.ReadSeeker, base int64, size int64, newline byte) *unitScanner {
+
+	return &unitScanner{
+		input: input,
+		base:  base,
+		size:  size,
+		isStart: func(prev byte, current byte) bool {
+			return prev == newline
+		},
+	}
+}
+
+func newUTF8CharScanner(input io.ReadSeeker, base int64, size int64) *unitScanner {
+
+	return &unitScanner{
+		input: input,
+		base:  base,
+		size:  size,
+		isStart: func(prev byte, current byte) bool {
+			return utf8.RuneStart(current)
+		},
+	}
+}
+
+// pos番目(負の場合は末尾から)の単位の開始位置を返します。
+// 単位が存在しない場合は、先頭より前であれば0、末尾より後ろであればsizeを返します。
+func (s *unitScanner) offset(pos int64) (int64, error) {
+
+	if pos > 0 {
+		return s.forward(pos)
+	}
+
+	return s.backward(-pos)
+}
+
+func (s *unitScanner) forward(pos int64) (int64, error) {
+
+	if pos == math.MaxInt64 {
+		return s.size, nil
+	}
+
+	if s.size == 0 {
+		return 0, nil
+	}
+
+	// 先頭は必ず単位の開始位置
+	count := int64(1)
+	if count == pos {
+		return 0, nil
+	}
+
+	buf := make([]byte, scanBufferSize)
+	prev := byte(0)
+	for offset := int64(0); offset < s.size; {
+		n, err := s.readAt(buf, offset)
+		if err != nil {
+			return 0, err
+		}
+
+		for i := range n {
+			current := buf[i]
+			if offset+int64(i) > 0 && s.isStart(prev, current) {
+				count++
+				if count == pos {
+					return offset + int64(i), nil
+				}
+			}
+			prev = current
+		}
+		offset += int64(n)
+	}
+
+	return s.size, nil
+}
+
+func (s *unitScanner) backward(pos int64) (int64, error) {
+
+	// 末尾から数えるので、pos==0は末尾
+	if pos == 0 {
+		return s.size, nil
+	}
+
+	buf := make([]byte, scanBufferSize)
+	count := int64(0)
+	for end := s.size; end > 0; {
+		// 直前のバイトも判断に使うので、1バイト多く読み込む
+		start := max(end-int64(len(buf))+1, 0)
+		readStart := max(start-1, 0)
+		n, err := s.readAt(buf[:end-readStart], readStart)
+		if err != nil {
+			return 0, err
+		}
+
+		for i := n - 1; i >= int(start-readStart); i-- {
+			offset := readStart + int64(i)
+			if offset == 0 {
+				break
+			}
+			if s.isStart(buf[i-1], buf[i]) {
+				count++
+				if count == pos {
+					return offset, nil
+				}
+			}
+		}
+		end = start
+	}
+
+	// 先頭は必ず単位の開始位置(足りない場合も先頭)
+	return 0, nil
+}
+
+func (s *unitScanner) readAt(buf []byte, offset int64) (int, error) {
+
+	if _, err := s.input.Seek(s.base+offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	size := min(int64(len(buf)), s.size-offset)
+	n, err := io.ReadFull(s.input, buf[:size])
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// 開始位置と終了位置に対応するバイト範囲をそのまま出力します。
+func (s *unitScanner) extract(output io.Writer, start int64, end int64) error {
+
+	startOffset, err := s.offset(start)
+	if err != nil {
+		return err
+	}
+
+	// 終了位置の次の単位の開始位置まで(-1の次と、指定が無い場合は末尾)
+	endOffset := s.size
+	if end != -1 && end != math.MaxInt64 {
+		endOffset, err = s.offset(end + 1)
+		if err != nil {
+			return err
+		}
+	}
+
+	if startOffset >= endOffset {
+		return nil
+	}
+
+	if _, err := s.input.Seek(s.base+startOffset, io.SeekStart); err != nil {
+		return err
+	}
+
+	_, err = io.CopyN(output, s.input, endOffset-startOffset)
+	return err
+}
@@ -0,0 +1,322 @@
+package extractor
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	enc "github.com/onozaty/filep/encoding"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// SizedUnits は、大きさを持つ単位(バイト、文字、行、表示幅)ごとに読み込むためのものです。
+type SizedUnits struct {
+	encoding encoding.Encoding
+	// 単位ごとに、内容と大きさを読み込む関数を作成(nilの場合はバイト単位)
+	newReader func(reader io.Reader) func() (string, int64, error)
+	// 単位の内容がデコードされたものか(falseの場合は元のバイト列)
+	decoded bool
+	// 行単位の場合の改行
+	newline Newline
+}
+
+func byteUnits(encoding encoding.Encoding) SizedUnits {
+
+	return SizedUnits{
+		encoding: encoding,
+	}
+}
+
+// 文字ごとの元のバイト列を単位とし、そのバイト数を大きさとします。
+func byteCharBoundaryUnits(encoding encoding.Encoding) SizedUnits {
+
+	return SizedUnits{
+		encoding: encoding,
+		newReader: func(reader io.Reader) func() (string, int64, error) {
+			chars := enc.NewCharReader(reader, encoding)
+			return func() (string, int64, error) {
+				_, raw, err := chars.ReadChar()
+				if err != nil {
+					return "", 0, err
+				}
+				return string(raw), int64(len(raw)), nil
+			}
+		},
+	}
+}
+
+func charUnits(unit CharUnit, encoding encoding.Encoding) SizedUnits {
+
+	return SizedUnits{
+		encoding: encoding,
+		decoded:  true,
+		newReader: func(reader io.Reader) func() (string, int64, error) {
+			next := unit.newReader(bufio.NewReader(reader))
+			return func() (string, int64, error) {
+				c, err := next()
+				return c, 1, err
+			}
+		},
+	}
+}
+
+func lineUnits(newline Newline, encoding encoding.Encoding) SizedUnits {
+
+	return SizedUnits{
+		encoding: encoding,
+		decoded:  true,
+		newline:  newline,
+		newReader: func(reader io.Reader) func() (string, int64, error) {
+			matcher := newline.newMatcher(bufio.NewReader(reader))
+			return func() (string, int64, error) {
+				line, err := matcher.readLine()
+				if err == io.EOF && line != "" {
+					// 改行で終わっていない最後の行
+					return line, 1, nil
+				}
+				return line, 1, err
+			}
+		},
+	}
+}
+
+// 行を単位とし、エンコーディングでのバイト数を大きさとします。
+func lineByteUnits(newline Newline, encoding encoding.Encoding) SizedUnits {
+
+	units := lineUnits(newline, encoding)
+	newLineReader := units.newReader
+	units.newReader = func(reader io.Reader) func() (string, int64, error) {
+		next := newLineReader(reader)
+		return func() (string, int64, error) {
+			line, _, err := next()
+			if err != nil {
+				return "", 0, err
+			}
+			encoded, err := encoding.NewEncoder().String(line)
+			if err != nil {
+				return "", 0, err
+			}
+			return line, int64(len(encoded)), nil
+		}
+	}
+
+	return units
+}
+
+// 文字を単位とし、エンコーディングでのバイト数を大きさとします。
+// 順にエンコードした際のバイト数のため、ISO-2022-JPのように最後に状態を戻すエスケープシーケンスが付くものは、その分を含みません。
+func charByteUnits(encoding encoding.Encoding) SizedUnits {
+
+	return SizedUnits{
+		encoding: encoding,
+		decoded:  true,
+		newReader: func(reader io.Reader) func() (string, int64, error) {
+			runes := bufio.NewReader(reader)
+			counter := &countingWriter{}
+			encoder := transform.NewWriter(counter, encoding.NewEncoder())
+			return func() (string, int64, error) {
+				c, _, err := runes.ReadRune()
+				if err != nil {
+					return "", 0, err
+				}
+
+				written := counter.size
+				if _, err := encoder.Write(utf8.AppendRune(nil, c)); err != nil {
+					return "", 0, err
+				}
+				return string(c), counter.size - written, nil
+			}
+		},
+	}
+}
+
+// 書き込まれた大きさだけを数えるWriterです。
+type countingWriter struct {
+	size int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+
+	w.size += int64(len(p))
+	return len(p), nil
+}
+
+func widthUnits(ambiguousWidth int64, encoding encoding.Encoding) SizedUnits {
+
+	return SizedUnits{
+		encoding: encoding,
+		decoded:  true,
+		newReader: func(reader io.Reader) func() (string, int64, error) {
+			runes := bufio.NewReader(reader)
+			return func() (string, int64, error) {
+				c, _, err := runes.ReadRune()
+				if err != nil {
+					return "", 0, err
+				}
+				return string(c), runeWidth(c, ambiguousWidth), nil
+			}
+		},
+	}
+}
+
+// NewByteUnits は、バイトを単位とするSizedUnitsを作成します。
+// マーカーなどは指定されたエンコーディングで出力します。
+func NewByteUnits(encodingName string) (SizedUnits, error) {
+
+	encoding, err := enc.Encoding(encodingName)
+	if err != nil {
+		return SizedUnits{}, err
+	}
+
+	return byteUnits(encoding), nil
+}
+
+// NewByteCharBoundaryUnits は、バイトを単位としながら、文字の途中で区切らないSizedUnitsを作成します。
+func NewByteCharBoundaryUnits(encodingName string) (SizedUnits, error) {
+
+	encoding, err := enc.Encoding(encodingName)
+	if err != nil {
+		return SizedUnits{}, err
+	}
+
+	return byteCharBoundaryUnits(encoding), nil
+}
+
+// NewCharUnits は、文字を単位とするSizedUnitsを作成します。
+func NewCharUnits(unit CharUnit, encodingName string) (SizedUnits, error) {
+
+	encoding, err := enc.Encoding(encodingName)
+	if err != nil {
+		return SizedUnits{}, err
+	}
+
+	return charUnits(unit, encoding), nil
+}
+
+// NewLineUnits は、行を単位とするSizedUnitsを作成します。
+// マーカーは1行として扱い、後ろには省略した行と同じ改行を付けます。
+func NewLineUnits(newline Newline, encodingName string) (SizedUnits, error) {
+
+	encoding, err := enc.Encoding(encodingName)
+	if err != nil {
+		return SizedUnits{}, err
+	}
+
+	return lineUnits(newline, encoding), nil
+}
+
+// NewWholeLineByteUnits は、行を単位とし、エンコーディングでのバイト数を大きさとするSizedUnitsを作成します。
+func NewWholeLineByteUnits(newline Newline, encodingName string) (SizedUnits, error) {
+
+	encoding, err := enc.Encoding(encodingName)
+	if err != nil {
+		return SizedUnits{}, err
+	}
+
+	return lineByteUnits(newline, encoding), nil
+}
+
+// NewWidthUnits は、文字を単位とし、表示幅(全角は2、半角は1)を大きさとするSizedUnitsを作成します。
+func NewWidthUnits(ambiguousWidth int, encodingName string) (SizedUnits, error) {
+
+	if err := validateAmbiguousWidth(ambiguousWidth); err != nil {
+		return SizedUnits{}, err
+	}
+
+	encoding, err := enc.Encoding(encodingName)
+	if err != nil {
+		return SizedUnits{}, err
+	}
+
+	return widthUnits(int64(ambiguousWidth), encoding), nil
+}
+
+// Reader は、入力を単位ごとに読み込み、その内容と大きさを返す関数を作成します。
+// 終端に達した場合はio.EOFを返します。
+func (u SizedUnits) Reader(input io.Reader) func() (string, int64, error) {
+
+	if u.decoded {
+		input = transform.NewReader(input, u.encoding.NewDecoder())
+	}
+
+	if u.newReader == nil {
+		bytes := bufio.NewReader(input)
+		return func() (string, int64, error) {
+			b, err := bytes.ReadByte()
+			if err != nil {
+				return "", 0, err
+			}
+			return string([]byte{b}), 1, nil
+		}
+	}
+
+	return u.newReader(input)
+}
+
+// Writer は、Readerで読み込んだ単位を出力先に書き込むためのWriterを作成します。
+// エンコーディングの状態を出力しきるために、書き込み後はCloseする必要があります。(出力先は閉じません)
+func (u SizedUnits) Writer(output io.Writer) io.WriteCloser {
+
+	if u.decoded {
+		return transform.NewWriter(output, u.encoding.NewEncoder())
+	}
+
+	return nopWriteCloser{output}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// 入力を単位ごとに読み込む関数と、単位を出力するためのWriterを返します。
+func (u SizedUnits) open(input io.Reader, output io.Writer) (func() (string, int64, error), *bufio.Writer) {
+
+	if u.decoded {
+		output = transform.NewWriter(output, u.encoding.NewEncoder())
+	}
+
+	return u.Reader(input), bufio.NewWriter(output)
+}
+
+// マーカーなどの文字列を、単位と同じ形式(デコードされたものか、元のバイト列か)に変換します。
+func (u SizedUnits) convert(str string) (string, error) {
+
+	if u.decoded {
+		return str, nil
+	}
+
+	return u.encoding.NewEncoder().String(str)
+}
+
+// 文字列の大きさを返します。
+func (u SizedUnits) size(str string) (int64, error) {
+
+	converted, err := u.convert(str)
+	if err != nil {
+		return 0, err
+	}
+
+	if u.newReader == nil {
+		return int64(len(converted)), nil
+	}
+
+	next := u.newReader(strings.NewReader(converted))
+	var total int64
+	for {
+		_, size, err := next()
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		total += size
+	}
+}
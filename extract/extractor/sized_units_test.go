@@ -0,0 +1,38 @@
+package extractor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWidthUnits_InvalidAmbiguousWidth(t *testing.T) {
+
+	for _, ambiguousWidth := range []int{0, 3} {
+		// ACT
+		_, err := NewWidthUnits(ambiguousWidth, "UTF-8")
+
+		// ASSERT
+		assert.EqualError(t, err, "ambiguous width must be 1 or 2", "ambiguousWidth=%d", ambiguousWidth)
+	}
+}
+
+func TestNewSizedUnits_InvalidEncoding(t *testing.T) {
+
+	constructors := map[string]func() (SizedUnits, error){
+		"byte":               func() (SizedUnits, error) { return NewByteUnits("utf") },
+		"byte char boundary": func() (SizedUnits, error) { return NewByteCharBoundaryUnits("utf") },
+		"char":               func() (SizedUnits, error) { return NewCharUnits(CharUnitRune, "utf") },
+		"line":               func() (SizedUnits, error) { return NewLineUnits(NewlineLF, "utf") },
+		"whole line byte":    func() (SizedUnits, error) { return NewWholeLineByteUnits(NewlineLF, "utf") },
+		"width":              func() (SizedUnits, error) { return NewWidthUnits(1, "utf") },
+	}
+
+	for name, constructor := range constructors {
+		// ACT
+		_, err := constructor()
+
+		// ASSERT
+		assert.EqualError(t, err, "utf is invalid: htmlindex: invalid encoding name", name)
+	}
+}
@@ -0,0 +1,48 @@
+package extractor
+
+import (
+	"io"
+
+	enc "github.com/onozaty/filep/encoding"
+)
+
+// バイト単位の範囲内に全体が収まる行だけを取り出すExtractorです。
+type wholeLineByteExtractor struct {
+	ranges []Range
+	units  SizedUnits
+}
+
+// NewWholeLineByteExtractor は、バイトで指定された範囲内に全体が収まる行を取り出すExtractorを作成します。
+// バイト数は、各行をエンコーディングでエンコードした長さです。
+func NewWholeLineByteExtractor(start int64, end int64, newline Newline, encodingName string) (Extractor, error) {
+
+	if err := validateRange(start, end); err != nil {
+		return nil, err
+	}
+
+	encoding, err := enc.Encoding(encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &wholeLineByteExtractor{
+		ranges: []Range{{Start: start, End: end}},
+		units:  lineByteUnits(newline, encoding),
+	}, nil
+}
+
+func (t *wholeLineByteExtractor) Extract(input io.Reader, output io.Writer) error {
+
+	next, writer := t.units.open(input, output)
+
+	write := func(line string) error {
+		_, err := writer.WriteString(line)
+		return err
+	}
+
+	if err := extractSizedUnits(next, write, t.ranges); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
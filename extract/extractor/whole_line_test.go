@@ -0,0 +1,104 @@
+package extractor
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestNewWholeLineByteExtractor(t *testing.T) {
+
+	// 各行は4バイト、7バイト、3バイト
+	contents := "abc\nあい\n12\n"
+
+	tests := []struct {
+		start    int64
+		end      int64
+		expected string
+	}{
+		{start: 1, end: math.MaxInt64, expected: "abc\nあい\n12\n"},
+		{start: 1, end: 4, expected: "abc\n"},
+		{start: 1, end: 9, expected: "abc\n"},
+		{start: 1, end: 10, expected: "abc\n"},
+		{start: 1, end: 11, expected: "abc\nあい\n"},
+		{start: 1, end: 3, expected: ""},
+		{start: -3, end: -1, expected: "12\n"},
+		{start: -8, end: -1, expected: "12\n"},
+		{start: -9, end: -1, expected: "12\n"},
+		{start: -10, end: -1, expected: "あい\n12\n"},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		extractor, err := NewWholeLineByteExtractor(tt.start, tt.end, NewlineLF, "UTF-8")
+		require.NoError(t, err)
+
+		output := new(bytes.Buffer)
+
+		// ACT
+		err = extractor.Extract(strings.NewReader(contents), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, output.String(), "start=%d, end=%d", tt.start, tt.end)
+	}
+}
+
+func TestNewWholeLineByteExtractor_SJIS(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewWholeLineByteExtractor(1, 9, NewlineCRLF, "sjis")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	// Shift_JISでは各行6バイト
+	err = extractor.Extract(bytes.NewReader(test.StringToByte(t, "あい\r\nうえ\r\n", japanese.ShiftJIS)), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "あい\r\n", test.ByteToString(t, output.Bytes(), japanese.ShiftJIS))
+}
+
+func TestNewWholeLineByteHeadTailExtractor(t *testing.T) {
+
+	// ARRANGE
+	units, err := NewWholeLineByteUnits(NewlineLF, "UTF-8")
+	require.NoError(t, err)
+	extractor, err := NewHeadTailExtractor(5, 5, "[%d bytes]", units)
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("abc\nあい\nxyz\n12\n"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "abc\n[11 bytes]\n12\n", output.String())
+}
+
+func TestNewWholeLineByteHeadSuffixExtractor(t *testing.T) {
+
+	// ARRANGE
+	units, err := NewWholeLineByteUnits(NewlineLF, "UTF-8")
+	require.NoError(t, err)
+	extractor, err := NewHeadSuffixExtractor(11, "...\n", true, units)
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("abc\nあい\nxyz\n"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "abc\n...\n", output.String())
+}
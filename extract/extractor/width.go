@@ -0,0 +1,111 @@
+package extractor
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"unicode"
+
+	enc "github.com/onozaty/filep/encoding"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/width"
+)
+
+type widthExtractor struct {
+	ranges []Range
+	// East Asian Widthが曖昧(Ambiguous)な文字の幅
+	ambiguousWidth int64
+	encoding       encoding.Encoding
+}
+
+// NewWidthExtractor は、表示幅(全角は2、半角は1)で指定された範囲内に収まる文字を取り出すExtractorを作成します。
+func NewWidthExtractor(start int64, end int64, ambiguousWidth int, encodingName string) (Extractor, error) {
+
+	if err := validateRange(start, end); err != nil {
+		return nil, err
+	}
+
+	return newWidthExtractor([]Range{{Start: start, End: end}}, ambiguousWidth, encodingName)
+}
+
+func NewWidthRangesExtractor(ranges []Range, ambiguousWidth int, encodingName string) (Extractor, error) {
+
+	if err := validateRanges(ranges); err != nil {
+		return nil, err
+	}
+
+	return newWidthExtractor(ranges, ambiguousWidth, encodingName)
+}
+
+func newWidthExtractor(ranges []Range, ambiguousWidth int, encodingName string) (Extractor, error) {
+
+	if err := validateAmbiguousWidth(ambiguousWidth); err != nil {
+		return nil, err
+	}
+
+	encoding, err := enc.Encoding(encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &widthExtractor{
+		ranges:         ranges,
+		ambiguousWidth: int64(ambiguousWidth),
+		encoding:       encoding,
+	}, nil
+}
+
+// East Asian Widthが曖昧(Ambiguous)な文字の幅として、1か2が指定されているかを確認します。
+func validateAmbiguousWidth(ambiguousWidth int) error {
+
+	if ambiguousWidth != 1 && ambiguousWidth != 2 {
+		return fmt.Errorf("ambiguous width must be 1 or 2")
+	}
+
+	return nil
+}
+
+func (t *widthExtractor) Extract(input io.Reader, output io.Writer) error {
+
+	reader := bufio.NewReader(transform.NewReader(input, t.encoding.NewDecoder()))
+	writer := bufio.NewWriter(transform.NewWriter(output, t.encoding.NewEncoder()))
+
+	next := func() (rune, int64, error) {
+		c, _, err := reader.ReadRune()
+		if err != nil {
+			return 0, 0, err
+		}
+		return c, runeWidth(c, t.ambiguousWidth), nil
+	}
+
+	write := func(c rune) error {
+		_, err := writer.WriteRune(c)
+		return err
+	}
+
+	if err := extractSizedUnits(next, write, t.ranges); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+// East Asian Widthを元に、文字の表示幅を返します。
+// 改行などの制御文字や、結合文字、ゼロ幅の文字は0とします。
+func runeWidth(c rune, ambiguousWidth int64) int64 {
+
+	if unicode.In(c, unicode.Cc, unicode.Cf, unicode.Mn, unicode.Me) {
+		return 0
+	}
+
+	switch width.LookupRune(c).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	case width.EastAsianAmbiguous:
+		return ambiguousWidth
+	default:
+		return 1
+	}
+}
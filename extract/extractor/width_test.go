@@ -0,0 +1,182 @@
+package extractor
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestNewWidthExtractor(t *testing.T) {
+
+	// "a"は1桁目、"あ"は2-3桁目、"ｲ"は4桁目、"う"は5-6桁目、"b"は7桁目
+	contents := "aあｲうb"
+
+	tests := []struct {
+		start    int64
+		end      int64
+		expected string
+	}{
+		{start: 1, end: math.MaxInt64, expected: "aあｲうb"},
+		{start: 1, end: 4, expected: "aあｲ"},
+		{start: 1, end: 5, expected: "aあｲ"},
+		{start: 3, end: 6, expected: "ｲう"},
+		{start: 2, end: 2, expected: ""},
+		{start: -3, end: -1, expected: "うb"},
+		{start: -4, end: -2, expected: "ｲう"},
+		{start: 2, end: -3, expected: "あｲ"},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		extractor, err := NewWidthExtractor(tt.start, tt.end, 1, "UTF-8")
+		require.NoError(t, err)
+
+		output := new(bytes.Buffer)
+
+		// ACT
+		err = extractor.Extract(strings.NewReader(contents), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, output.String(), "start=%d, end=%d", tt.start, tt.end)
+	}
+}
+
+func TestNewWidthExtractor_ZeroWidth(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewWidthExtractor(1, 4, 1, "UTF-8")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("か\u3099\nきく"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	// 結合文字や改行は幅0で、直前の文字と同じ位置として扱う
+	assert.Equal(t, "か\u3099\nき", output.String())
+}
+
+func TestNewWidthExtractor_LeadingZeroWidth(t *testing.T) {
+
+	// 先頭にある幅0の文字は、最初の位置にあるものとして扱う
+	tests := []struct {
+		contents string
+		start    int64
+		end      int64
+		expected string
+	}{
+		{contents: "\nabc", start: 1, end: 2, expected: "\nab"},
+		{contents: "\ufeffabc", start: 1, end: 100, expected: "\ufeffabc"},
+		{contents: "\u0301\u0302abc", start: 1, end: 2, expected: "\u0301\u0302ab"},
+		{contents: "\nabc", start: 2, end: 3, expected: "bc"},
+		{contents: "\nabc", start: -3, end: -1, expected: "\nabc"},
+		{contents: "\nabc", start: -2, end: -1, expected: "bc"},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		extractor, err := NewWidthExtractor(tt.start, tt.end, 1, "UTF-8")
+		require.NoError(t, err)
+
+		output := new(bytes.Buffer)
+
+		// ACT
+		err = extractor.Extract(strings.NewReader(tt.contents), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, output.String(), "contents=%q, start=%d, end=%d", tt.contents, tt.start, tt.end)
+	}
+}
+
+func TestNewWidthExtractor_Ambiguous(t *testing.T) {
+
+	{
+		// ARRANGE
+		extractor, err := NewWidthExtractor(1, 3, 1, "UTF-8")
+		require.NoError(t, err)
+
+		output := new(bytes.Buffer)
+
+		// ACT
+		err = extractor.Extract(strings.NewReader("○△□"), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "○△□", output.String())
+	}
+	{
+		// ARRANGE
+		extractor, err := NewWidthExtractor(1, 3, 2, "UTF-8")
+		require.NoError(t, err)
+
+		output := new(bytes.Buffer)
+
+		// ACT
+		err = extractor.Extract(strings.NewReader("○△□"), output)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "○", output.String())
+	}
+}
+
+func TestNewWidthExtractor_SJIS(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewWidthExtractor(-5, -1, 1, "sjis")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(io.MultiReader(bytes.NewReader(test.StringToByte(t, "あいうｴｵ漢字", japanese.ShiftJIS))), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "ｵ漢字", test.ByteToString(t, output.Bytes(), japanese.ShiftJIS))
+}
+
+func TestNewWidthRangesExtractor(t *testing.T) {
+
+	// ARRANGE
+	extractor, err := NewWidthRangesExtractor([]Range{{Start: 1, End: 2}, {Start: 5, End: 8}}, 1, "UTF-8")
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+
+	// ACT
+	err = extractor.Extract(strings.NewReader("あいうえお"), output)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "あうえ", output.String())
+}
+
+func TestNewWidthExtractor_InvalidAmbiguousWidth(t *testing.T) {
+
+	// ACT
+	_, err := NewWidthExtractor(1, 2, 3, "UTF-8")
+
+	// ASSERT
+	assert.EqualError(t, err, "ambiguous width must be 1 or 2")
+}
+
+func TestNewWidthExtractor_InvalidEncoding(t *testing.T) {
+
+	// ACT
+	_, err := NewWidthExtractor(1, 2, 1, "utf")
+
+	// ASSERT
+	assert.EqualError(t, err, "utf is invalid: htmlindex: invalid encoding name")
+}
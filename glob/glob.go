@@ -0,0 +1,73 @@
+package glob
+
+import (
+	"path"
+	"strings"
+)
+
+// Pattern は / 区切りのパスに対するglobパターンです。
+// path.Match の構文に加えて、任意の階層にマッチする ** を使用できます。
+type Pattern struct {
+	segments []string
+}
+
+func Compile(pattern string) (*Pattern, error) {
+
+	pattern = strings.TrimPrefix(strings.Trim(pattern, "/"), "./")
+	segments := strings.Split(pattern, "/")
+
+	for _, segment := range segments {
+		if segment == "**" {
+			continue
+		}
+		// パターンとして不正なものをここで検出しておく
+		if _, err := path.Match(segment, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Pattern{
+		segments: segments,
+	}, nil
+}
+
+func (p *Pattern) Match(name string) bool {
+
+	return matchSegments(p.segments, strings.Split(strings.Trim(name, "/"), "/"))
+}
+
+func matchSegments(patterns []string, names []string) bool {
+
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			// 連続する ** はまとめて扱う
+			for len(patterns) > 0 && patterns[0] == "**" {
+				patterns = patterns[1:]
+			}
+			if len(patterns) == 0 {
+				return true
+			}
+			// 0個以上の階層を読み飛ばして残りがマッチするか
+			for i := 0; i <= len(names); i++ {
+				if matchSegments(patterns, names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(names) == 0 {
+			return false
+		}
+
+		matched, _ := path.Match(patterns[0], names[0])
+		if !matched {
+			return false
+		}
+
+		patterns = patterns[1:]
+		names = names[1:]
+	}
+
+	return len(names) == 0
+}
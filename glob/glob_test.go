@@ -0,0 +1,91 @@
+package glob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPattern(t *testing.T) {
+
+	{
+		pattern, err := Compile("*.txt")
+		require.NoError(t, err)
+
+		assert.True(t, pattern.Match("a.txt"))
+		assert.False(t, pattern.Match("a.txt.bak"))
+		assert.False(t, pattern.Match("dir/a.txt"))
+	}
+	{
+		pattern, err := Compile("dir/?.txt")
+		require.NoError(t, err)
+
+		assert.True(t, pattern.Match("dir/a.txt"))
+		assert.False(t, pattern.Match("dir/ab.txt"))
+		assert.False(t, pattern.Match("a.txt"))
+		assert.False(t, pattern.Match("dir/sub/a.txt"))
+	}
+	{
+		pattern, err := Compile("[ab].txt")
+		require.NoError(t, err)
+
+		assert.True(t, pattern.Match("a.txt"))
+		assert.True(t, pattern.Match("b.txt"))
+		assert.False(t, pattern.Match("c.txt"))
+	}
+}
+
+func TestPattern_DoubleStar(t *testing.T) {
+
+	{
+		pattern, err := Compile("**/*.txt")
+		require.NoError(t, err)
+
+		assert.True(t, pattern.Match("a.txt"))
+		assert.True(t, pattern.Match("dir/a.txt"))
+		assert.True(t, pattern.Match("dir/sub/a.txt"))
+		assert.False(t, pattern.Match("dir/a.go"))
+	}
+	{
+		pattern, err := Compile("dir/**")
+		require.NoError(t, err)
+
+		assert.True(t, pattern.Match("dir"))
+		assert.True(t, pattern.Match("dir/a.txt"))
+		assert.True(t, pattern.Match("dir/sub/a.txt"))
+		assert.False(t, pattern.Match("other/a.txt"))
+	}
+	{
+		pattern, err := Compile("a/**/b/**/c")
+		require.NoError(t, err)
+
+		assert.True(t, pattern.Match("a/b/c"))
+		assert.True(t, pattern.Match("a/x/b/y/z/c"))
+		assert.False(t, pattern.Match("a/x/c"))
+	}
+	{
+		pattern, err := Compile("**/**/.git")
+		require.NoError(t, err)
+
+		assert.True(t, pattern.Match(".git"))
+		assert.True(t, pattern.Match("sub/.git"))
+		assert.False(t, pattern.Match("sub/.gitignore"))
+	}
+}
+
+func TestPattern_Normalize(t *testing.T) {
+
+	pattern, err := Compile("./dir/*.txt/")
+	require.NoError(t, err)
+
+	assert.True(t, pattern.Match("dir/a.txt"))
+	assert.True(t, pattern.Match("/dir/a.txt/"))
+}
+
+func TestPattern_Invalid(t *testing.T) {
+
+	_, err := Compile("dir/[a")
+
+	assert.EqualError(t, err, "syntax error in pattern")
+}
@@ -0,0 +1,337 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type op struct {
+	kind opKind
+	// 変更前、変更後それぞれの行の位置(0始まり)
+	oldIndex int
+	newIndex int
+}
+
+// Unified は2つのテキストを行単位で比較し、unified形式の差分を返します。
+// 差分が無い場合は空文字を返します。
+func Unified(oldName string, newName string, oldText string, newText string, context int) string {
+
+	oldLines := splitLines(oldText)
+	newLines := splitLines(newText)
+
+	ops := diffLines(oldLines, newLines)
+
+	hunks := buildHunks(ops, context)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "--- %s\n", oldName)
+	fmt.Fprintf(&builder, "+++ %s\n", newName)
+
+	for _, hunk := range hunks {
+		writeHunk(&builder, hunk, oldLines, newLines)
+	}
+
+	return builder.String()
+}
+
+// 改行を含めたまま行に分割します。
+func splitLines(text string) []string {
+
+	lines := []string{}
+	for len(text) > 0 {
+		i := strings.IndexByte(text, '\n')
+		if i == -1 {
+			lines = append(lines, text)
+			break
+		}
+		lines = append(lines, text[:i+1])
+		text = text[i+1:]
+	}
+
+	return lines
+}
+
+// Myersのアルゴリズムで、行単位の編集手順を求めます。
+func diffLines(oldLines []string, newLines []string) []op {
+
+	// 先頭と末尾の一致している部分は比較対象から外しておく
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	ops := []op{}
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, op{kind: opEqual, oldIndex: i, newIndex: i})
+	}
+
+	a := oldLines[prefix : len(oldLines)-suffix]
+	b := newLines[prefix : len(newLines)-suffix]
+	for _, o := range myers(a, b) {
+		o.oldIndex += prefix
+		o.newIndex += prefix
+		ops = append(ops, o)
+	}
+
+	for i := 0; i < suffix; i++ {
+		ops = append(ops, op{
+			kind:     opEqual,
+			oldIndex: len(oldLines) - suffix + i,
+			newIndex: len(newLines) - suffix + i,
+		})
+	}
+
+	return ops
+}
+
+// 線形空間版のMyersのアルゴリズムで、編集手順を求めます。
+// 中央のスネークで分割しながら再帰的に求めるので、編集距離に関わらずメモリはO(N+M)で済みます。
+func myers(a []string, b []string) []op {
+
+	size := (len(a)+len(b)+1)/2 + 1
+	m := &myersDiff{
+		a:        a,
+		b:        b,
+		offset:   size,
+		forward:  make([]int, 2*size+1),
+		backward: make([]int, 2*size+1),
+		ops:      []op{},
+	}
+	m.compare(0, len(a), 0, len(b))
+
+	return m.ops
+}
+
+type myersDiff struct {
+	a []string
+	b []string
+	// 対角線kに対する、前方と後方(逆順にたどった場合)それぞれの最も遠くまで到達したx
+	// (添字はoffset+k、分割した範囲ごとに使いまわす)
+	offset   int
+	forward  []int
+	backward []int
+	ops      []op
+}
+
+// a[aLo:aHi]とb[bLo:bHi]の編集手順を、順番に追加します。
+func (m *myersDiff) compare(aLo int, aHi int, bLo int, bHi int) {
+
+	// 先頭と末尾の一致している部分
+	for aLo < aHi && bLo < bHi && m.a[aLo] == m.b[bLo] {
+		m.ops = append(m.ops, op{kind: opEqual, oldIndex: aLo, newIndex: bLo})
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi && bLo < bHi && m.a[aHi-1] == m.b[bHi-1] {
+		aHi--
+		bHi--
+		suffix++
+	}
+
+	switch {
+	case aLo == aHi:
+		for y := bLo; y < bHi; y++ {
+			m.ops = append(m.ops, op{kind: opInsert, oldIndex: aLo, newIndex: y})
+		}
+	case bLo == bHi:
+		for x := aLo; x < aHi; x++ {
+			m.ops = append(m.ops, op{kind: opDelete, oldIndex: x, newIndex: bLo})
+		}
+	default:
+		// 中央のスネークの前後をそれぞれ求める
+		x, y, u, v := m.middleSnake(aLo, aHi, bLo, bHi)
+		m.compare(aLo, x, bLo, y)
+		for ; x < u; x, y = x+1, y+1 {
+			m.ops = append(m.ops, op{kind: opEqual, oldIndex: x, newIndex: y})
+		}
+		m.compare(u, aHi, v, bHi)
+	}
+
+	for i := range suffix {
+		m.ops = append(m.ops, op{kind: opEqual, oldIndex: aHi + i, newIndex: bHi + i})
+	}
+}
+
+// 前方と後方から同時にたどり、最短の編集手順の中央にあるスネーク(一致が続く部分)を求めます。
+// スネークの開始位置(x, y)と終了位置(u, v)を返します。
+func (m *myersDiff) middleSnake(aLo int, aHi int, bLo int, bHi int) (int, int, int, int) {
+
+	a := m.a[aLo:aHi]
+	b := m.b[bLo:bHi]
+	n := len(a)
+	l := len(b)
+	delta := n - l
+	odd := delta%2 != 0
+
+	vf := m.forward
+	vb := m.backward
+	offset := m.offset
+	vf[offset+1] = 0
+	vb[offset+1] = 0
+
+	// 編集距離の半分までたどれば、前方と後方は必ず重なる
+	for d := 0; ; d++ {
+		// 前方から
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && vf[offset+k-1] < vf[offset+k+1]) {
+				x = vf[offset+k+1] // 下へ(挿入)
+			} else {
+				x = vf[offset+k-1] + 1 // 右へ(削除)
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < l && a[x] == b[y] {
+				x++
+				y++
+			}
+			vf[offset+k] = x
+
+			// 後方からd-1ステップでたどった範囲と重なったか
+			if odd && delta-k >= -(d-1) && delta-k <= d-1 && x+vb[offset+delta-k] >= n {
+				return aLo + startX, bLo + startY, aLo + x, bLo + y
+			}
+		}
+
+		// 後方から(末尾からの位置で扱う)
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && vb[offset+k-1] < vb[offset+k+1]) {
+				x = vb[offset+k+1]
+			} else {
+				x = vb[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < l && a[n-1-x] == b[l-1-y] {
+				x++
+				y++
+			}
+			vb[offset+k] = x
+
+			// 前方からdステップでたどった範囲と重なったか
+			if !odd && delta-k >= -d && delta-k <= d && x+vf[offset+delta-k] >= n {
+				return aLo + n - x, bLo + l - y, aLo + n - startX, bLo + l - startY
+			}
+		}
+	}
+}
+
+type hunk struct {
+	ops []op
+}
+
+func buildHunks(ops []op, context int) []hunk {
+
+	hunks := []hunk{}
+
+	i := 0
+	for i < len(ops) {
+		// 次の変更箇所を探す
+		for i < len(ops) && ops[i].kind == opEqual {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+
+		// 変更箇所の間の一致行がcontextの2倍以下なら同じハンクにまとめる
+		end := i
+		for end < len(ops) {
+			for end < len(ops) && ops[end].kind != opEqual {
+				end++
+			}
+			equals := 0
+			for end+equals < len(ops) && ops[end+equals].kind == opEqual {
+				equals++
+			}
+			if end+equals == len(ops) || equals > context*2 {
+				if equals > context {
+					equals = context
+				}
+				end += equals
+				break
+			}
+			end += equals
+		}
+
+		hunks = append(hunks, hunk{ops: ops[start:end]})
+		i = end
+	}
+
+	return hunks
+}
+
+func writeHunk(builder *strings.Builder, h hunk, oldLines []string, newLines []string) {
+
+	oldStart := h.ops[0].oldIndex
+	newStart := h.ops[0].newIndex
+	oldCount := 0
+	newCount := 0
+	for _, o := range h.ops {
+		if o.kind != opInsert {
+			oldCount++
+		}
+		if o.kind != opDelete {
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(builder, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+
+	for _, o := range h.ops {
+		switch o.kind {
+		case opEqual:
+			writeLine(builder, " ", oldLines[o.oldIndex])
+		case opDelete:
+			writeLine(builder, "-", oldLines[o.oldIndex])
+		case opInsert:
+			writeLine(builder, "+", newLines[o.newIndex])
+		}
+	}
+}
+
+func hunkRange(start int, count int) string {
+
+	switch count {
+	case 0:
+		// 空の範囲は直前の行番号で表す
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+func writeLine(builder *strings.Builder, prefix string, line string) {
+
+	builder.WriteString(prefix)
+	builder.WriteString(line)
+
+	if !strings.HasSuffix(line, "\n") {
+		builder.WriteString("\n\\ No newline at end of file\n")
+	}
+}
@@ -0,0 +1,225 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified(t *testing.T) {
+
+	result := Unified(
+		"a.txt", "b.txt",
+		"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+		"1\n2\n3\n4\nx\n6\n7\n8\n9\n10\n",
+		3)
+
+	assert.Equal(
+		t,
+		"--- a.txt\n"+
+			"+++ b.txt\n"+
+			"@@ -2,7 +2,7 @@\n"+
+			" 2\n"+
+			" 3\n"+
+			" 4\n"+
+			"-5\n"+
+			"+x\n"+
+			" 6\n"+
+			" 7\n"+
+			" 8\n",
+		result)
+}
+
+func TestUnified_MultipleHunks(t *testing.T) {
+
+	result := Unified(
+		"a.txt", "b.txt",
+		"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+		"x\n2\n3\n4\n5\n6\n7\n8\n9\n10\ny\n",
+		1)
+
+	assert.Equal(
+		t,
+		"--- a.txt\n"+
+			"+++ b.txt\n"+
+			"@@ -1,2 +1,2 @@\n"+
+			"-1\n"+
+			"+x\n"+
+			" 2\n"+
+			"@@ -10 +10,2 @@\n"+
+			" 10\n"+
+			"+y\n",
+		result)
+}
+
+func TestUnified_MergeHunks(t *testing.T) {
+
+	// 変更箇所の間がcontextの2倍以下なら1つのハンクに
+	result := Unified(
+		"a.txt", "b.txt",
+		"1\n2\n3\n4\n5\n6\n",
+		"x\n2\n3\n4\n5\ny\n",
+		2)
+
+	assert.Equal(
+		t,
+		"--- a.txt\n"+
+			"+++ b.txt\n"+
+			"@@ -1,6 +1,6 @@\n"+
+			"-1\n"+
+			"+x\n"+
+			" 2\n"+
+			" 3\n"+
+			" 4\n"+
+			" 5\n"+
+			"-6\n"+
+			"+y\n",
+		result)
+}
+
+func TestUnified_InsertDelete(t *testing.T) {
+
+	{
+		result := Unified("a", "b", "", "1\n2\n", 3)
+		assert.Equal(t, "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+1\n+2\n", result)
+	}
+	{
+		result := Unified("a", "b", "1\n2\n", "", 3)
+		assert.Equal(t, "--- a\n+++ b\n@@ -1,2 +0,0 @@\n-1\n-2\n", result)
+	}
+	{
+		result := Unified("a", "b", "1\n3\n", "1\n2\n3\n", 0)
+		assert.Equal(t, "--- a\n+++ b\n@@ -1,0 +2 @@\n+2\n", result)
+	}
+}
+
+func TestUnified_NoNewlineAtEnd(t *testing.T) {
+
+	result := Unified("a", "b", "1\n2", "1\n3", 3)
+
+	assert.Equal(
+		t,
+		"--- a\n"+
+			"+++ b\n"+
+			"@@ -1,2 +1,2 @@\n"+
+			" 1\n"+
+			"-2\n"+
+			"\\ No newline at end of file\n"+
+			"+3\n"+
+			"\\ No newline at end of file\n",
+		result)
+}
+
+func TestDiffLines(t *testing.T) {
+
+	oldLines := splitLines("a\nb\nc\na\nb\nb\na\n")
+	newLines := splitLines("c\nb\na\nb\na\nc\n")
+
+	ops := diffLines(oldLines, newLines)
+
+	// 編集手順から変更前と変更後の内容が復元できること
+	edits := assertOps(t, oldLines, newLines, ops)
+	// 最短の編集手順であること
+	assert.Equal(t, 5, edits)
+}
+
+func TestUnified_Same(t *testing.T) {
+
+	result := Unified("a", "b", "1\n2\n", "1\n2\n", 3)
+
+	assert.Equal(t, "", result)
+}
+
+func TestDiffLines_Random(t *testing.T) {
+
+	random := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, random.Intn(20))
+		for i := range lines {
+			lines[i] = string(rune('a' + random.Intn(3)))
+		}
+		return lines
+	}
+
+	for range 500 {
+		// ARRANGE
+		oldLines := randomLines()
+		newLines := randomLines()
+
+		// ACT
+		ops := diffLines(oldLines, newLines)
+
+		// ASSERT
+		edits := assertOps(t, oldLines, newLines, ops)
+		// 最短の編集手順であること(LCSから求めた編集距離と一致)
+		assert.Equal(t, len(oldLines)+len(newLines)-2*lcsLength(oldLines, newLines), edits, "old=%v, new=%v", oldLines, newLines)
+	}
+}
+
+func TestUnified_Large(t *testing.T) {
+
+	// ARRANGE
+	// 全ての行が変更されている大きな入力でも、編集距離に比例したメモリを使わずに求められること
+	var oldText, newText strings.Builder
+	for i := range 8000 {
+		fmt.Fprintf(&oldText, "old line %d\n", i)
+		fmt.Fprintf(&newText, "new line %d\n", i)
+	}
+
+	// ACT
+	result := Unified("a", "b", oldText.String(), newText.String(), 3)
+
+	// ASSERT
+	assert.True(t, strings.HasPrefix(result, "--- a\n+++ b\n@@ -1,8000 +1,8000 @@\n-old line 0\n"))
+	assert.Equal(t, 8000, strings.Count(result, "\n-old line "))
+	assert.Equal(t, 8000, strings.Count(result, "\n+new line "))
+}
+
+// 編集手順から変更前と変更後の内容が復元できることを確認し、編集の数を返します。
+func assertOps(t *testing.T, oldLines []string, newLines []string, ops []op) int {
+
+	restoredOld := []string{}
+	restoredNew := []string{}
+	edits := 0
+	for _, o := range ops {
+		switch o.kind {
+		case opEqual:
+			assert.Equal(t, oldLines[o.oldIndex], newLines[o.newIndex])
+			restoredOld = append(restoredOld, oldLines[o.oldIndex])
+			restoredNew = append(restoredNew, newLines[o.newIndex])
+		case opDelete:
+			restoredOld = append(restoredOld, oldLines[o.oldIndex])
+			edits++
+		case opInsert:
+			restoredNew = append(restoredNew, newLines[o.newIndex])
+			edits++
+		}
+	}
+
+	assert.Equal(t, oldLines, restoredOld)
+	assert.Equal(t, newLines, restoredNew)
+
+	return edits
+}
+
+func lcsLength(a []string, b []string) int {
+
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				table[i+1][j+1] = table[i][j] + 1
+			} else {
+				table[i+1][j+1] = max(table[i][j+1], table[i+1][j])
+			}
+		}
+	}
+
+	return table[len(a)][len(b)]
+}
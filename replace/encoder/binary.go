@@ -3,6 +3,7 @@ package encoder
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"strings"
 )
 
@@ -42,6 +43,85 @@ func (e *BinaryEncoder) Bytes(src string) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+func (e *BinaryEncoder) NewReader(r io.Reader) io.Reader {
+
+	return &binaryReader{
+		reader: r,
+	}
+}
+
+func (e *BinaryEncoder) NewWriter(w io.Writer) io.WriteCloser {
+
+	return &binaryWriter{
+		writer:  w,
+		encoder: e,
+	}
+}
+
+type binaryReader struct {
+	reader io.Reader
+	buf    []byte
+}
+
+func (r *binaryReader) Read(p []byte) (int, error) {
+
+	// 1バイトが3文字になるため、読み込むバイト数はpの1/3まで
+	size := len(p) / 3
+	if size == 0 {
+		return 0, io.ErrShortBuffer
+	}
+
+	if len(r.buf) < size {
+		r.buf = make([]byte, size)
+	}
+
+	n, err := r.reader.Read(r.buf[:size])
+	for i, b := range r.buf[:n] {
+		copy(p[i*3:], byteToHex(b))
+	}
+
+	return n * 3, err
+}
+
+type binaryWriter struct {
+	writer  io.Writer
+	encoder *BinaryEncoder
+	pending []byte
+}
+
+func (w *binaryWriter) Write(p []byte) (int, error) {
+
+	w.pending = append(w.pending, p...)
+
+	// 3文字単位で変換し、端数は次の書き込みまで持ち越す
+	size := len(w.pending) / 3 * 3
+	if size == 0 {
+		return len(p), nil
+	}
+
+	bytes, err := w.encoder.Bytes(string(w.pending[:size]))
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := w.writer.Write(bytes); err != nil {
+		return 0, err
+	}
+
+	w.pending = append(w.pending[:0], w.pending[size:]...)
+
+	return len(p), nil
+}
+
+func (w *binaryWriter) Close() error {
+
+	if len(w.pending) != 0 {
+		return fmt.Errorf("illegal hex string \"%s\"", w.pending)
+	}
+
+	return nil
+}
+
 const hextable = "0123456789ABCDEF"
 
 func byteToHex(b byte) string {
@@ -1,6 +1,8 @@
 package encoder
 
 import (
+	"bytes"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -138,3 +140,44 @@ func TestNewEncoder_Binary_Invalid(t *testing.T) {
 	require.Error(t, err)
 	assert.Equal(t, `illegal hex string "xF"`, err.Error())
 }
+
+func TestBinaryEncoder_Stream(t *testing.T) {
+
+	encoder := &BinaryEncoder{}
+
+	{
+		result, err := io.ReadAll(encoder.NewReader(bytes.NewReader([]byte{0x00, 0x01, 0xFF})))
+		require.NoError(t, err)
+		assert.Equal(t, "x00x01xFF", string(result))
+	}
+
+	{
+		buf := new(bytes.Buffer)
+		writer := encoder.NewWriter(buf)
+		// ヘキサ文字の途中で区切って書き込み
+		for _, s := range []string{"x0", "0x01", "x", "FF"} {
+			_, err := writer.Write([]byte(s))
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+		assert.Equal(t, []byte{0x00, 0x01, 0xFF}, buf.Bytes())
+	}
+}
+
+func TestBinaryEncoder_Stream_Invalid(t *testing.T) {
+
+	encoder := &BinaryEncoder{}
+
+	{
+		writer := encoder.NewWriter(new(bytes.Buffer))
+		_, err := writer.Write([]byte("x0G"))
+		assert.EqualError(t, err, "illegal hex string \"x0G\"")
+	}
+
+	{
+		writer := encoder.NewWriter(new(bytes.Buffer))
+		_, err := writer.Write([]byte("x00x0"))
+		require.NoError(t, err)
+		assert.EqualError(t, writer.Close(), "illegal hex string \"x0\"")
+	}
+}
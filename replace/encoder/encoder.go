@@ -1,27 +1,40 @@
 package encoder
 
 import (
+	"io"
 	"strings"
 
 	enc "github.com/onozaty/filep/encoding"
 
 	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
 )
 
 type Encoder interface {
 	String([]byte) (string, error)
 	Bytes(string) ([]byte, error)
+	// バイト列を読み込みながら文字列(UTF-8)に変換するReaderを返します。
+	NewReader(io.Reader) io.Reader
+	// 書き込まれた文字列(UTF-8)をバイト列に変換しながら書き込むWriterを返します。
+	// 最後にCloseを呼び出す必要があります。
+	NewWriter(io.Writer) io.WriteCloser
 }
 
 func NewEncoder(name string) (Encoder, error) {
 
-	if strings.ToLower(name) == "binary" {
+	if IsBinary(name) {
 		return &BinaryEncoder{}, nil
 	}
 
 	return newEncodingEncoder(name)
 }
 
+// IsBinary はエンコーディング名が、バイト列をヘキサ文字として扱うbinaryかを返します。
+func IsBinary(name string) bool {
+
+	return strings.ToLower(name) == "binary"
+}
+
 func newEncodingEncoder(name string) (*EncodingEncoder, error) {
 
 	encoding, err := enc.Encoding(name)
@@ -52,3 +65,13 @@ func (e *EncodingEncoder) Bytes(src string) ([]byte, error) {
 
 	return e.encoding.NewEncoder().Bytes([]byte(src))
 }
+
+func (e *EncodingEncoder) NewReader(r io.Reader) io.Reader {
+
+	return transform.NewReader(r, e.encoding.NewDecoder())
+}
+
+func (e *EncodingEncoder) NewWriter(w io.Writer) io.WriteCloser {
+
+	return transform.NewWriter(w, e.encoding.NewEncoder())
+}
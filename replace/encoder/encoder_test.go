@@ -1,7 +1,10 @@
 package encoder
 
 import (
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -60,3 +63,33 @@ func TestNewEncoder_Invalid(t *testing.T) {
 	require.Error(t, err)
 	assert.EqualError(t, err, "xxxx is invalid: htmlindex: invalid encoding name")
 }
+
+func TestNewEncoder_SJIS_Stream(t *testing.T) {
+
+	// ARRANGE
+	str := "あいうえお"
+	bytes := []byte{'\x82', '\xA0', '\x82', '\xA2', '\x82', '\xA4', '\x82', '\xA6', '\x82', '\xA8'}
+
+	// ACT / ASSERT
+	encoder, err := NewEncoder("sjis")
+	require.NoError(t, err)
+
+	{
+		// 1バイトずつ読み込まれても文字列に変換できること
+		result, err := io.ReadAll(encoder.NewReader(iotest.OneByteReader(strings.NewReader(string(bytes)))))
+		require.NoError(t, err)
+		assert.Equal(t, str, string(result))
+	}
+
+	{
+		buf := new(strings.Builder)
+		writer := encoder.NewWriter(buf)
+		// 文字の途中で区切って書き込み
+		_, err := writer.Write([]byte(str[:4]))
+		require.NoError(t, err)
+		_, err = writer.Write([]byte(str[4:]))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		assert.Equal(t, string(bytes), buf.String())
+	}
+}
@@ -1,5 +1,14 @@
 package replacer
 
 type Replacer interface {
-	Replace(string) string
+	// 置換後の文字列と、置換した件数を返します。
+	Replace(string) (string, int)
+}
+
+type StreamReplacer interface {
+	// 文字列を追加し、置換が確定した部分の文字列と、置換した件数を返します。
+	// 確定できなかった部分は、次の追加時またはFlushまで保持されます。
+	Push(string) (string, int)
+	// 保持している残りの文字列を置換した文字列と、置換した件数を返します。
+	Flush() (string, int)
 }
@@ -0,0 +1,64 @@
+package replacer
+
+type chainReplacer struct {
+	replacers []Replacer
+}
+
+// NewChainReplacer は複数のReplacerを順番に適用するReplacerを作成します。
+func NewChainReplacer(replacers ...Replacer) Replacer {
+
+	return &chainReplacer{
+		replacers: replacers,
+	}
+}
+
+func (r *chainReplacer) Replace(s string) (string, int) {
+
+	total := 0
+	for _, replacer := range r.replacers {
+		var count int
+		s, count = replacer.Replace(s)
+		total += count
+	}
+
+	return s, total
+}
+
+type chainStreamReplacer struct {
+	replacers []StreamReplacer
+}
+
+// NewChainStreamReplacer は複数のStreamReplacerを順番に適用するStreamReplacerを作成します。
+func NewChainStreamReplacer(replacers ...StreamReplacer) StreamReplacer {
+
+	return &chainStreamReplacer{
+		replacers: replacers,
+	}
+}
+
+func (r *chainStreamReplacer) Push(s string) (string, int) {
+
+	total := 0
+	for _, replacer := range r.replacers {
+		var count int
+		s, count = replacer.Push(s)
+		total += count
+	}
+
+	return s, total
+}
+
+func (r *chainStreamReplacer) Flush() (string, int) {
+
+	// 前のReplacerが出力した残りを、後ろのReplacerに渡してから確定させる
+	s := ""
+	total := 0
+	for _, replacer := range r.replacers {
+		pushed, pushedCount := replacer.Push(s)
+		flushed, flushedCount := replacer.Flush()
+		s = pushed + flushed
+		total += pushedCount + flushedCount
+	}
+
+	return s, total
+}
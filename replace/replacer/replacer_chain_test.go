@@ -0,0 +1,54 @@
+package replacer
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainReplacer(t *testing.T) {
+
+	replacer := NewChainReplacer(
+		NewStringReplacer("a", "b"),
+		NewRegexpReplacer(regexp.MustCompile("b+"), "c"),
+		NewStringReplacer("c", "dd"),
+	)
+
+	{
+		// 前の置換結果に対して次の置換が行われる
+		result, count := replacer.Replace("abab")
+		assert.Equal(t, "dd", result)
+		assert.Equal(t, 2+1+1, count)
+	}
+	{
+		result, count := replacer.Replace("xyz")
+		assert.Equal(t, "xyz", result)
+		assert.Equal(t, 0, count)
+	}
+}
+
+func TestChainReplacer_Empty(t *testing.T) {
+
+	replacer := NewChainReplacer()
+
+	result, count := replacer.Replace("abc")
+	assert.Equal(t, "abc", result)
+	assert.Equal(t, 0, count)
+}
+
+func TestChainStreamReplacer(t *testing.T) {
+
+	input := "abab\nab"
+	for size := 1; size <= len(input); size++ {
+		replacer := NewChainStreamReplacer(
+			NewStringStreamReplacer("a", "b"),
+			NewRegexpLineStreamReplacer(regexp.MustCompile("b+"), "c"),
+			NewStringStreamReplacer("c", "dd"),
+		)
+
+		result, count := pushChunks(replacer, input, size)
+		assert.Equal(t, "dd\ndd", result, "size=%d", size)
+		assert.Equal(t, 3+2+2, count, "size=%d", size)
+	}
+}
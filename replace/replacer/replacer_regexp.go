@@ -1,7 +1,11 @@
 package replacer
 
 import (
+	"fmt"
 	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
 )
 
 type regexpReplacer struct {
@@ -17,6 +21,265 @@ func NewRegexpReplacer(regex *regexp.Regexp, replacement string) Replacer {
 	}
 }
 
-func (r *regexpReplacer) Replace(s string) string {
-	return r.regex.ReplaceAllString(s, r.replacement)
+func (r *regexpReplacer) Replace(s string) (string, int) {
+
+	// 件数を数えるため、ReplaceAllStringと同じ処理をマッチ位置から組み立てる
+	matches := r.regex.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s, 0
+	}
+
+	return r.expand(s, matches), len(matches)
+}
+
+func (r *regexpReplacer) expand(s string, matches [][]int) string {
+
+	replaced := []byte{}
+	last := 0
+	for _, match := range matches {
+		replaced = append(replaced, s[last:match[0]]...)
+		replaced = r.regex.ExpandString(replaced, r.replacement, s, match)
+		last = match[1]
+	}
+	replaced = append(replaced, s[last:]...)
+
+	return string(replaced)
+}
+
+type regexpLineStreamReplacer struct {
+	regexpReplacer
+	pending string
+}
+
+// NewRegexpLineStreamReplacer は行(\n区切り)ごとに置換を行うStreamReplacerを作成します。
+// 行ごとに独立して置換するため、^ と $ は行の先頭と末尾にマッチし、行をまたいだマッチは行われません。
+func NewRegexpLineStreamReplacer(regex *regexp.Regexp, replacement string) StreamReplacer {
+
+	return &regexpLineStreamReplacer{
+		regexpReplacer: regexpReplacer{
+			regex:       regex,
+			replacement: replacement,
+		},
+	}
+}
+
+func (r *regexpLineStreamReplacer) Push(s string) (string, int) {
+
+	text := r.pending + s
+
+	// 改行が来るまでは行が確定しないため保持
+	end := strings.LastIndexByte(text, '\n') + 1
+	r.pending = text[end:]
+
+	return r.replaceLines(text[:end])
+}
+
+func (r *regexpLineStreamReplacer) Flush() (string, int) {
+
+	text := r.pending
+	r.pending = ""
+
+	return r.replaceLines(text)
+}
+
+func (r *regexpLineStreamReplacer) replaceLines(text string) (string, int) {
+
+	var builder strings.Builder
+	total := 0
+	for text != "" {
+		line, rest, found := strings.Cut(text, "\n")
+
+		replaced, count := r.Replace(line)
+		builder.WriteString(replaced)
+		if found {
+			builder.WriteByte('\n')
+		}
+
+		total += count
+		text = rest
+	}
+
+	return builder.String(), total
+}
+
+type regexpWindowStreamReplacer struct {
+	regexpReplacer
+	maxMatchLength int
+	// 直前の文字を参照する正規表現の場合に、直前の1文字を含めて検索するための正規表現
+	contextRegex *regexp.Regexp
+	pending      string
+	// 置換済みとしたものの最後の1文字(pendingの直前の文字)
+	behind string
+	// 置換済みとしたものの最後がマッチの終わりか(その位置での空のマッチは行わない)
+	afterMatch bool
+}
+
+// NewRegexpWindowStreamReplacer はマッチの長さが最大maxMatchLength文字であるとして、
+// その分の文字列を保持しながら置換を行うStreamReplacerを作成します。
+func NewRegexpWindowStreamReplacer(regex *regexp.Regexp, replacement string, maxMatchLength int) (StreamReplacer, error) {
+
+	re, err := syntax.Parse(regex.String(), syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+
+	// 文字列全体の先頭/末尾は、途中で区切って置換すると判断できない
+	if containsOp(re, syntax.OpBeginText, syntax.OpEndText) {
+		return nil, fmt.Errorf("\\A, \\z, or ^, $ without (?m) cannot be used with --max-match-length")
+	}
+
+	var contextRegex *regexp.Regexp
+	if containsOp(re, syntax.OpBeginLine, syntax.OpWordBoundary, syntax.OpNoWordBoundary) {
+		// 区切った位置で直前の文字を参照できるように、直前の1文字を含めてマッチさせる
+		// (グループは追加しないので、グループの番号や名前は元の正規表現と同じ)
+		contextRegex, err = regexp.Compile(`(?s:.)(?:` + regex.String() + `)`)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &regexpWindowStreamReplacer{
+		regexpReplacer: regexpReplacer{
+			regex:       regex,
+			replacement: replacement,
+		},
+		maxMatchLength: maxMatchLength,
+		contextRegex:   contextRegex,
+	}, nil
+}
+
+func (r *regexpWindowStreamReplacer) Push(s string) (string, int) {
+
+	text := r.pending + s
+
+	// 末尾のmaxMatchLength文字は、次に追加される文字列と合わせてマッチする可能性があるため保持
+	cut := len(text)
+	for i := 0; i < r.maxMatchLength && cut > 0; i++ {
+		_, size := utf8.DecodeLastRuneInString(text[:cut])
+		cut -= size
+	}
+
+	matches := r.findAll(text)
+
+	// 区切り位置をまたぐマッチがある場合は、そのマッチの手前で区切る
+	for _, match := range matches {
+		if match[0] < cut && match[1] > cut {
+			cut = match[0]
+			break
+		}
+	}
+
+	committed := [][]int{}
+	for _, match := range matches {
+		if match[0] >= cut {
+			break
+		}
+		committed = append(committed, match)
+	}
+
+	if cut > 0 {
+		_, size := utf8.DecodeLastRuneInString(text[:cut])
+		r.behind = text[cut-size : cut]
+		r.afterMatch = len(committed) > 0 && committed[len(committed)-1][1] == cut
+	}
+	r.pending = text[cut:]
+
+	return r.expand(text[:cut], committed), len(committed)
+}
+
+func (r *regexpWindowStreamReplacer) Flush() (string, int) {
+
+	text := r.pending
+	matches := r.findAll(text)
+
+	r.pending = ""
+	r.behind = ""
+	r.afterMatch = false
+
+	return r.expand(text, matches), len(matches)
+}
+
+// 置換済みとしたものに続けて、textの中のマッチを全て探します。
+// 入力全体に対してFindAllStringSubmatchIndexを行った場合と同じマッチとなるようにします。
+func (r *regexpWindowStreamReplacer) findAll(text string) [][]int {
+
+	if r.contextRegex == nil || r.behind == "" {
+		matches := r.regex.FindAllStringSubmatchIndex(text, -1)
+		if r.afterMatch && len(matches) > 0 && matches[0][0] == 0 && matches[0][1] == 0 {
+			// 直前のマッチの終わりと接する空のマッチ
+			matches = matches[1:]
+		}
+		return matches
+	}
+
+	// 直前の1文字を含めた文字列の中で、1つずつ探す
+	withBehind := r.behind + text
+	base := len(r.behind)
+
+	matches := [][]int{}
+	prevMatchEnd := -1
+	if r.afterMatch {
+		prevMatchEnd = base
+	}
+	for pos := base; pos <= len(withBehind); {
+		_, behindSize := utf8.DecodeLastRuneInString(withBehind[:pos])
+		from := pos - behindSize
+
+		match := r.contextRegex.FindStringSubmatchIndex(withBehind[from:])
+		if match == nil {
+			break
+		}
+
+		// 直前の1文字を除いた、元の正規表現のマッチ位置に
+		_, size := utf8.DecodeRuneInString(withBehind[from+match[0]:])
+		match[0] += size
+		for i := range match {
+			if match[i] >= 0 {
+				match[i] += from
+			}
+		}
+
+		accept := true
+		if match[1] == pos {
+			// 空のマッチは直前のマッチと接していない場合のみとし、次の文字へ
+			accept = match[0] != prevMatchEnd
+			if pos == len(withBehind) {
+				pos++
+			} else {
+				_, size := utf8.DecodeRuneInString(withBehind[pos:])
+				pos += size
+			}
+		} else {
+			pos = match[1]
+		}
+		prevMatchEnd = match[1]
+
+		if accept {
+			for i := range match {
+				if match[i] >= 0 {
+					match[i] -= base
+				}
+			}
+			matches = append(matches, match)
+		}
+	}
+
+	return matches
+}
+
+func containsOp(re *syntax.Regexp, ops ...syntax.Op) bool {
+
+	for _, op := range ops {
+		if re.Op == op {
+			return true
+		}
+	}
+
+	for _, sub := range re.Sub {
+		if containsOp(sub, ops...) {
+			return true
+		}
+	}
+
+	return false
 }
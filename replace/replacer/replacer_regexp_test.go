@@ -2,9 +2,11 @@ package replacer
 
 import (
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRegexpReplacer(t *testing.T) {
@@ -12,20 +14,24 @@ func TestRegexpReplacer(t *testing.T) {
 	replacer := NewRegexpReplacer(regexp.MustCompile("[a-z]{2}"), "xx")
 
 	{
-		result := replacer.Replace("abc")
+		result, count := replacer.Replace("abc")
 		assert.Equal(t, "xxc", result)
+		assert.Equal(t, 1, count)
 	}
 	{
-		result := replacer.Replace("abcd")
+		result, count := replacer.Replace("abcd")
 		assert.Equal(t, "xxxx", result)
+		assert.Equal(t, 2, count)
 	}
 	{
-		result := replacer.Replace("a")
+		result, count := replacer.Replace("a")
 		assert.Equal(t, "a", result)
+		assert.Equal(t, 0, count)
 	}
 	{
-		result := replacer.Replace("")
+		result, count := replacer.Replace("")
 		assert.Equal(t, "", result)
+		assert.Equal(t, 0, count)
 	}
 }
 
@@ -34,19 +40,157 @@ func TestRegexpReplacer_BackReference(t *testing.T) {
 	replacer := NewRegexpReplacer(regexp.MustCompile("X([0-9]+)"), "Z$1")
 
 	{
-		result := replacer.Replace("X123X")
+		result, count := replacer.Replace("X123X")
 		assert.Equal(t, "Z123X", result)
+		assert.Equal(t, 1, count)
 	}
 	{
-		result := replacer.Replace("X1X2X3X")
+		result, count := replacer.Replace("X1X2X3X")
 		assert.Equal(t, "Z1Z2Z3X", result)
+		assert.Equal(t, 3, count)
 	}
 	{
-		result := replacer.Replace("a")
+		result, count := replacer.Replace("a")
 		assert.Equal(t, "a", result)
+		assert.Equal(t, 0, count)
 	}
 	{
-		result := replacer.Replace("")
+		result, count := replacer.Replace("")
 		assert.Equal(t, "", result)
+		assert.Equal(t, 0, count)
 	}
 }
+
+func TestRegexpReplacer_EmptyMatch(t *testing.T) {
+
+	regex := regexp.MustCompile("a*")
+	replacer := NewRegexpReplacer(regex, "x")
+
+	// ReplaceAllStringと同じ結果になること
+	for _, s := range []string{"baaac", "", "aaa", "bcd"} {
+		result, count := replacer.Replace(s)
+		assert.Equal(t, regex.ReplaceAllString(s, "x"), result)
+		assert.Equal(t, len(regex.FindAllString(s, -1)), count)
+	}
+}
+
+func TestRegexpLineStreamReplacer(t *testing.T) {
+
+	// 行ごとに置換されるため、$ は各行の末尾にマッチ
+	input := "abc\nabc\n\naa"
+	for size := 1; size <= len(input); size++ {
+		result, count := pushChunks(NewRegexpLineStreamReplacer(regexp.MustCompile("a$|^$"), "x"), input, size)
+		assert.Equal(t, "abc\nabc\nx\nax", result, "size=%d", size)
+		assert.Equal(t, 2, count, "size=%d", size)
+	}
+}
+
+func TestRegexpWindowStreamReplacer(t *testing.T) {
+
+	// マッチの長さが最大文字数以内であれば、まとめて置換した場合と同じ結果になること
+	input := "あいう123えお45\n6789かき0"
+	regex := regexp.MustCompile("[0-9]+")
+	expected, expectedCount := NewRegexpReplacer(regex, "<$0>").Replace(input)
+
+	for size := 1; size <= len(input); size++ {
+		replacer, err := NewRegexpWindowStreamReplacer(regex, "<$0>", 4)
+		require.NoError(t, err)
+
+		result, count := pushChunks(replacer, input, size)
+		assert.Equal(t, expected, result, "size=%d", size)
+		assert.Equal(t, expectedCount, count, "size=%d", size)
+	}
+}
+
+func TestRegexpWindowStreamReplacer_MultiLine(t *testing.T) {
+
+	// 行をまたいだマッチ
+	input := "a\nb\nca\nb\n"
+	regex := regexp.MustCompile("a\nb")
+
+	for size := 1; size <= len(input); size++ {
+		replacer, err := NewRegexpWindowStreamReplacer(regex, "-", 3)
+		require.NoError(t, err)
+
+		result, count := pushChunks(replacer, input, size)
+		assert.Equal(t, "-\nc-\n", result, "size=%d", size)
+		assert.Equal(t, 2, count, "size=%d", size)
+	}
+}
+
+func TestRegexpWindowStreamReplacer_LineBoundary(t *testing.T) {
+
+	// 直前の文字を参照する \b や (?m)^ も、まとめて置換した場合と同じ結果になること
+	input := "cat concat\ncat\ncatalog cat"
+	regex := regexp.MustCompile("(?m)^cat|\\bcat\\b")
+	expected, expectedCount := NewRegexpReplacer(regex, "dog").Replace(input)
+
+	for size := 1; size <= len(input); size++ {
+		replacer, err := NewRegexpWindowStreamReplacer(regex, "dog", 3)
+		require.NoError(t, err)
+
+		result, count := pushChunks(replacer, input, size)
+		assert.Equal(t, expected, result, "size=%d", size)
+		assert.Equal(t, expectedCount, count, "size=%d", size)
+	}
+}
+
+func TestRegexpWindowStreamReplacer_Context(t *testing.T) {
+
+	// 区切った位置の直前の文字を参照するものや空のマッチも、まとめて置換した場合と同じ結果になること
+	input := "ab abb\nba aab\n\nb a"
+	for _, expr := range []string{"\\bab*\\b", "\\Ba", "(?m)^a|b$", "(?m)^", "\\b", "b*", "(?m)^b*"} {
+		regex := regexp.MustCompile(expr)
+		expected, expectedCount := NewRegexpReplacer(regex, "<$0>").Replace(input)
+
+		for size := 1; size <= len(input); size++ {
+			replacer, err := NewRegexpWindowStreamReplacer(regex, "<$0>", 3)
+			require.NoError(t, err)
+
+			result, count := pushChunks(replacer, input, size)
+			assert.Equal(t, expected, result, "expr=%s, size=%d", expr, size)
+			assert.Equal(t, expectedCount, count, "expr=%s, size=%d", expr, size)
+		}
+	}
+}
+
+func TestRegexpWindowStreamReplacer_NoNewline(t *testing.T) {
+
+	// 改行が無い長い入力でも、保持する文字列が増え続けないこと
+	for _, expr := range []string{"\\bcat\\b", "(?m)^cat", "cat"} {
+		regex := regexp.MustCompile(expr)
+		replacer, err := NewRegexpWindowStreamReplacer(regex, "dog", 5)
+		require.NoError(t, err)
+
+		chunk := strings.Repeat("cat concat ", 100)
+		result := ""
+		total := 0
+		for range 100 {
+			replaced, count := replacer.Push(chunk)
+			result += replaced
+			total += count
+
+			// 最大文字数と、区切り位置をまたぐマッチの分まで
+			assert.LessOrEqual(t, len(replacer.(*regexpWindowStreamReplacer).pending), 5*2, "expr=%s", expr)
+		}
+		replaced, count := replacer.Flush()
+		result += replaced
+		total += count
+
+		expected, expectedCount := NewRegexpReplacer(regex, "dog").Replace(strings.Repeat(chunk, 100))
+		assert.Equal(t, expected, result, "expr=%s", expr)
+		assert.Equal(t, expectedCount, total, "expr=%s", expr)
+	}
+}
+
+func TestRegexpWindowStreamReplacer_TextAnchor(t *testing.T) {
+
+	for _, expr := range []string{"^a", "a$", "\\Aa", "a\\z"} {
+		_, err := NewRegexpWindowStreamReplacer(regexp.MustCompile(expr), "", 1)
+		assert.EqualError(t, err, "\\A, \\z, or ^, $ without (?m) cannot be used with --max-match-length", expr)
+	}
+
+	// 複数行モードであれば利用可能
+	_, err := NewRegexpWindowStreamReplacer(regexp.MustCompile("(?m)^a$"), "", 1)
+	assert.NoError(t, err)
+}
@@ -15,6 +15,62 @@ func NewStringReplacer(old string, new string) Replacer {
 	}
 }
 
-func (r *stringReplacer) Replace(s string) string {
-	return strings.ReplaceAll(s, r.old, r.new)
+func (r *stringReplacer) Replace(s string) (string, int) {
+
+	count := strings.Count(s, r.old)
+	if count == 0 {
+		return s, 0
+	}
+
+	return strings.ReplaceAll(s, r.old, r.new), count
+}
+
+type stringStreamReplacer struct {
+	stringReplacer
+	pending string
+}
+
+func NewStringStreamReplacer(old string, new string) StreamReplacer {
+
+	return &stringStreamReplacer{
+		stringReplacer: stringReplacer{
+			old: old,
+			new: new,
+		},
+	}
+}
+
+func (r *stringStreamReplacer) Push(s string) (string, int) {
+
+	text := r.pending + s
+
+	var builder strings.Builder
+	count := 0
+	pos := 0
+	for {
+		i := strings.Index(text[pos:], r.old)
+		if i == -1 {
+			break
+		}
+
+		builder.WriteString(text[pos : pos+i])
+		builder.WriteString(r.new)
+		pos += i + len(r.old)
+		count++
+	}
+
+	// 末尾は次に追加される文字列と合わせてマッチする可能性があるため保持
+	keep := max(pos, len(text)-(len(r.old)-1))
+	builder.WriteString(text[pos:keep])
+	r.pending = text[keep:]
+
+	return builder.String(), count
+}
+
+func (r *stringStreamReplacer) Flush() (string, int) {
+
+	text := r.pending
+	r.pending = ""
+
+	return r.Replace(text)
 }
@@ -11,19 +11,64 @@ func TestStringReplacer(t *testing.T) {
 	replacer := NewStringReplacer("abc", "xyz")
 
 	{
-		result := replacer.Replace("abc")
+		result, count := replacer.Replace("abc")
 		assert.Equal(t, "xyz", result)
+		assert.Equal(t, 1, count)
 	}
 	{
-		result := replacer.Replace(" abcabc\nabcABC\n")
+		result, count := replacer.Replace(" abcabc\nabcABC\n")
 		assert.Equal(t, " xyzxyz\nxyzABC\n", result)
+		assert.Equal(t, 3, count)
 	}
 	{
-		result := replacer.Replace("")
+		result, count := replacer.Replace("")
 		assert.Equal(t, "", result)
+		assert.Equal(t, 0, count)
 	}
 	{
-		result := replacer.Replace("aaaa")
+		result, count := replacer.Replace("aaaa")
 		assert.Equal(t, "aaaa", result)
+		assert.Equal(t, 0, count)
 	}
 }
+
+func TestStringStreamReplacer(t *testing.T) {
+
+	// 区切り位置を変えても、まとめて置換した場合と同じ結果になること
+	input := "abcabcaabcxabc"
+	for size := 1; size <= len(input); size++ {
+		result, count := pushChunks(NewStringStreamReplacer("abc", "Z"), input, size)
+		assert.Equal(t, "ZZaZxZ", result, "size=%d", size)
+		assert.Equal(t, 4, count, "size=%d", size)
+	}
+}
+
+func TestStringStreamReplacer_NoMatch(t *testing.T) {
+
+	replacer := NewStringStreamReplacer("abc", "Z")
+
+	{
+		result, count := replacer.Push("xyzab")
+		assert.Equal(t, "xyz", result)
+		assert.Equal(t, 0, count)
+	}
+	{
+		result, count := replacer.Flush()
+		assert.Equal(t, "ab", result)
+		assert.Equal(t, 0, count)
+	}
+}
+
+func pushChunks(replacer StreamReplacer, input string, size int) (string, int) {
+
+	result := ""
+	total := 0
+	for i := 0; i < len(input); i += size {
+		replaced, count := replacer.Push(input[i:min(i+size, len(input))])
+		result += replaced
+		total += count
+	}
+
+	replaced, count := replacer.Flush()
+	return result + replaced, total + count
+}
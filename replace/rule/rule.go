@@ -0,0 +1,239 @@
+package rule
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/onozaty/filep/escape"
+	"github.com/onozaty/filep/replace/replacer"
+	"github.com/pkg/errors"
+)
+
+const (
+	TypeRegex  = "regex"
+	TypeString = "string"
+)
+
+// Rule は置換ルールの1件分です。
+type Rule struct {
+	Type        string `json:"type"`
+	Target      string `json:"target"`
+	Replacement string `json:"replacement"`
+	Escape      bool   `json:"escape"`
+}
+
+// Load はルールファイルを読み込みます。
+// 拡張子が .json の場合はJSON、.tsv の場合はTSVとして扱います。
+func Load(path string) ([]Rule, error) {
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return loadJSON(path)
+	case ".tsv":
+		return loadTSV(path)
+	default:
+		return nil, fmt.Errorf("unsupported rules file format: %s (must be .json or .tsv)", path)
+	}
+}
+
+func loadJSON(path string) ([]Rule, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []Rule{}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, errors.WithMessagef(err, "could not parse rules file %s", path)
+	}
+
+	return rules, nil
+}
+
+// TSVは 種類、対象、置換後、エスケープ有無(省略可) の順で1行1ルールとします。
+// 空行と # で始まる行は読み飛ばします。
+func loadTSV(path string) ([]Rule, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rules := []Rule{}
+
+	// bufio.Scannerでは長い行を読み込めないため、行の長さに制限の無いReadStringを使う
+	reader := bufio.NewReader(file)
+	lineNum := 0
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF && line == "" {
+			break
+		}
+		if err != nil && err != io.EOF {
+			return nil, errors.WithMessagef(err, "could not read rules file %s", path)
+		}
+
+		lineNum++
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		columns := strings.Split(line, "\t")
+		if len(columns) != 3 && len(columns) != 4 {
+			return nil, fmt.Errorf("could not parse rules file %s: line %d: expected 3 or 4 columns, but got %d", path, lineNum, len(columns))
+		}
+
+		rule := Rule{
+			Type:        columns[0],
+			Target:      columns[1],
+			Replacement: columns[2],
+		}
+
+		if len(columns) == 4 && columns[3] != "" {
+			escape, err := strconv.ParseBool(columns[3])
+			if err != nil {
+				return nil, fmt.Errorf("could not parse rules file %s: line %d: invalid escape value %q", path, lineNum, columns[3])
+			}
+			rule.Escape = escape
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// Replacer はルールに従って置換を行うReplacerを作成します。
+func (r Rule) Replacer() (replacer.Replacer, error) {
+
+	target, replacement, regex, err := r.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	if regex != nil {
+		return replacer.NewRegexpReplacer(regex, replacement), nil
+	}
+
+	return replacer.NewStringReplacer(target, replacement), nil
+}
+
+func (r Rule) parse() (string, string, *regexp.Regexp, error) {
+
+	target, err := r.unescape(r.Target)
+	if err != nil {
+		return "", "", nil, errors.WithMessage(err, "could not parse target")
+	}
+
+	replacement, err := r.unescape(r.Replacement)
+	if err != nil {
+		return "", "", nil, errors.WithMessage(err, "could not parse replacement")
+	}
+
+	if target == "" {
+		return "", "", nil, fmt.Errorf("target must be specified")
+	}
+
+	switch r.Type {
+	case TypeRegex:
+		regex, err := regexp.Compile(target)
+		if err != nil {
+			return "", "", nil, errors.WithMessage(err, "regular expression is invalid")
+		}
+		return target, replacement, regex, nil
+	case TypeString:
+		return target, replacement, nil, nil
+	default:
+		return "", "", nil, fmt.Errorf("invalid type %q (must be %s or %s)", r.Type, TypeRegex, TypeString)
+	}
+}
+
+func (r Rule) unescape(str string) (string, error) {
+
+	if !r.Escape {
+		return str, nil
+	}
+
+	// \nのように指定されているものを、スケープ文字として扱えるように
+	return escape.Unescape(str)
+}
+
+// NewReplacer はルールを順番に適用するReplacerを作成します。
+func NewReplacer(rules []Rule) (replacer.Replacer, error) {
+
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("no rules are defined")
+	}
+
+	replacers := []replacer.Replacer{}
+	for i, rule := range rules {
+		r, err := rule.Replacer()
+		if err != nil {
+			return nil, errors.WithMessagef(err, "rule %d", i+1)
+		}
+		replacers = append(replacers, r)
+	}
+
+	return replacer.NewChainReplacer(replacers...), nil
+}
+
+// NewStreamReplacerFactory はルールを順番に適用するStreamReplacerを作成する関数を返します。
+// StreamReplacerは状態を持つため、ファイルごとに作成する必要があります。
+// maxMatchLength が0の場合、正規表現は行ごとに置換します。
+func NewStreamReplacerFactory(rules []Rule, maxMatchLength int) (func() (replacer.StreamReplacer, error), error) {
+
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("no rules are defined")
+	}
+
+	// ルールの解析は最初に1度だけ行う
+	factories := []func() (replacer.StreamReplacer, error){}
+	for i, rule := range rules {
+		target, replacement, regex, err := rule.parse()
+		if err != nil {
+			return nil, errors.WithMessagef(err, "rule %d", i+1)
+		}
+
+		factory := func() (replacer.StreamReplacer, error) {
+			if regex == nil {
+				return replacer.NewStringStreamReplacer(target, replacement), nil
+			}
+			if maxMatchLength == 0 {
+				return replacer.NewRegexpLineStreamReplacer(regex, replacement), nil
+			}
+			return replacer.NewRegexpWindowStreamReplacer(regex, replacement, maxMatchLength)
+		}
+
+		// 作成できることを確認しておく
+		if _, err := factory(); err != nil {
+			return nil, errors.WithMessagef(err, "rule %d", i+1)
+		}
+
+		factories = append(factories, factory)
+	}
+
+	return func() (replacer.StreamReplacer, error) {
+
+		replacers := []replacer.StreamReplacer{}
+		for _, factory := range factories {
+			r, err := factory()
+			if err != nil {
+				return nil, err
+			}
+			replacers = append(replacers, r)
+		}
+
+		return replacer.NewChainStreamReplacer(replacers...), nil
+	}, nil
+}
@@ -0,0 +1,252 @@
+package rule
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_JSON(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+	path := test.CreateFileWriteString(t, d, "rules.json", `[
+  {"type": "regex", "target": "a+", "replacement": "x"},
+  {"type": "string", "target": "\\t", "replacement": " ", "escape": true}
+]`)
+
+	// ACT
+	rules, err := Load(path)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, []Rule{
+		{Type: TypeRegex, Target: "a+", Replacement: "x"},
+		{Type: TypeString, Target: `\t`, Replacement: " ", Escape: true},
+	}, rules)
+}
+
+func TestLoad_JSON_Invalid(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+	path := test.CreateFileWriteString(t, d, "rules.json", `{"type": "regex"}`)
+
+	// ACT
+	_, err := Load(path)
+
+	// ASSERT
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "could not parse rules file "+path)
+}
+
+func TestLoad_TSV(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+	path := test.CreateFileWriteString(t, d, "rules.tsv",
+		"# コメント\r\n"+
+			"regex\ta+\tx\r\n"+
+			"\r\n"+
+			"string\t\\n\t \ttrue\r\n"+
+			"string\tb\t\t\r\n")
+
+	// ACT
+	rules, err := Load(path)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, []Rule{
+		{Type: TypeRegex, Target: "a+", Replacement: "x"},
+		{Type: TypeString, Target: `\n`, Replacement: " ", Escape: true},
+		{Type: TypeString, Target: "b", Replacement: ""},
+	}, rules)
+}
+
+func TestLoad_TSV_LongLine(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+	// bufio.Scannerの既定の上限(64KB)を超える行
+	target := strings.Repeat("a", 100*1024)
+	path := test.CreateFileWriteString(t, d, "rules.tsv", "string\t"+target+"\tb\nstring\tc\td")
+
+	// ACT
+	rules, err := Load(path)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, []Rule{
+		{Type: TypeString, Target: target, Replacement: "b"},
+		{Type: TypeString, Target: "c", Replacement: "d"},
+	}, rules)
+}
+
+func TestLoad_TSV_ReadError(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+	// ディレクトリは開けるが、読み込むとエラーになる
+	path := filepath.Join(d, "rules.tsv")
+	require.NoError(t, os.Mkdir(path, 0755))
+
+	// ACT
+	_, err := Load(path)
+
+	// ASSERT
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "could not read rules file "+path)
+}
+
+func TestLoad_TSV_InvalidColumns(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+	path := test.CreateFileWriteString(t, d, "rules.tsv", "regex\ta+\tx\nstring\ta\n")
+
+	// ACT
+	_, err := Load(path)
+
+	// ASSERT
+	assert.EqualError(t, err, "could not parse rules file "+path+": line 2: expected 3 or 4 columns, but got 2")
+}
+
+func TestLoad_TSV_InvalidEscape(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+	path := test.CreateFileWriteString(t, d, "rules.tsv", "regex\ta+\tx\tyes\n")
+
+	// ACT
+	_, err := Load(path)
+
+	// ASSERT
+	assert.EqualError(t, err, "could not parse rules file "+path+": line 1: invalid escape value \"yes\"")
+}
+
+func TestLoad_UnsupportedFormat(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+	path := test.CreateFileWriteString(t, d, "rules.txt", "")
+
+	// ACT
+	_, err := Load(path)
+
+	// ASSERT
+	assert.EqualError(t, err, "unsupported rules file format: "+path+" (must be .json or .tsv)")
+}
+
+func TestNewReplacer(t *testing.T) {
+
+	// ARRANGE
+	rules := []Rule{
+		{Type: TypeRegex, Target: "a+", Replacement: "x"},
+		{Type: TypeString, Target: `\n`, Replacement: `\t`, Escape: true},
+		{Type: TypeRegex, Target: "(x)(y)", Replacement: "$2$1"},
+	}
+
+	// ACT
+	replacer, err := NewReplacer(rules)
+
+	// ASSERT
+	require.NoError(t, err)
+
+	result, count := replacer.Replace("aay\naxy\n")
+	assert.Equal(t, "yx\txyx\t", result)
+	assert.Equal(t, 2+2+2, count)
+}
+
+func TestNewReplacer_Empty(t *testing.T) {
+
+	// ACT
+	_, err := NewReplacer([]Rule{})
+
+	// ASSERT
+	assert.EqualError(t, err, "no rules are defined")
+}
+
+func TestNewReplacer_InvalidType(t *testing.T) {
+
+	// ACT
+	_, err := NewReplacer([]Rule{
+		{Type: TypeString, Target: "a", Replacement: "b"},
+		{Type: "other", Target: "a", Replacement: "b"},
+	})
+
+	// ASSERT
+	assert.EqualError(t, err, `rule 2: invalid type "other" (must be regex or string)`)
+}
+
+func TestNewReplacer_InvalidRegex(t *testing.T) {
+
+	// ACT
+	_, err := NewReplacer([]Rule{
+		{Type: TypeRegex, Target: "[a", Replacement: "b"},
+	})
+
+	// ASSERT
+	assert.EqualError(t, err, "rule 1: regular expression is invalid: error parsing regexp: missing closing ]: `[a`")
+}
+
+func TestNewReplacer_EmptyTarget(t *testing.T) {
+
+	// ACT
+	_, err := NewReplacer([]Rule{
+		{Type: TypeString, Target: "", Replacement: "b"},
+	})
+
+	// ASSERT
+	assert.EqualError(t, err, "rule 1: target must be specified")
+}
+
+func TestNewReplacer_InvalidEscape(t *testing.T) {
+
+	// ACT
+	_, err := NewReplacer([]Rule{
+		{Type: TypeString, Target: "a", Replacement: `\`, Escape: true},
+	})
+
+	// ASSERT
+	assert.EqualError(t, err, "rule 1: could not parse replacement: invalid syntax")
+}
+
+func TestNewStreamReplacerFactory(t *testing.T) {
+
+	// ARRANGE
+	rules := []Rule{
+		{Type: TypeRegex, Target: "a+", Replacement: "x"},
+		{Type: TypeString, Target: `\n`, Replacement: `\t`, Escape: true},
+	}
+
+	// ACT
+	factory, err := NewStreamReplacerFactory(rules, 0)
+
+	// ASSERT
+	require.NoError(t, err)
+
+	replacer, err := factory()
+	require.NoError(t, err)
+
+	pushed, pushedCount := replacer.Push("aay\na")
+	flushed, flushedCount := replacer.Flush()
+	assert.Equal(t, "xy\tx", pushed+flushed)
+	assert.Equal(t, 3, pushedCount+flushedCount)
+}
+
+func TestNewStreamReplacerFactory_TextAnchor(t *testing.T) {
+
+	// ACT
+	_, err := NewStreamReplacerFactory([]Rule{
+		{Type: TypeString, Target: "a", Replacement: "b"},
+		{Type: TypeRegex, Target: "a$", Replacement: "b"},
+	}, 10)
+
+	// ASSERT
+	assert.EqualError(t, err, "rule 2: \\A, \\z, or ^, $ without (?m) cannot be used with --max-match-length")
+}
@@ -0,0 +1,70 @@
+package splitter
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/onozaty/filep/extract/extractor"
+)
+
+type byteSplitter struct {
+	size int64
+}
+
+func NewByteSplitter(size int64) (Splitter, error) {
+
+	if err := validateSize(size); err != nil {
+		return nil, err
+	}
+
+	return &byteSplitter{
+		size: size,
+	}, nil
+}
+
+// 文字の途中で分割しないように、指定バイト数以下で分割します。
+func NewByteCharBoundarySplitter(size int64, encodingName string) (Splitter, error) {
+
+	if err := validateSize(size); err != nil {
+		return nil, err
+	}
+
+	units, err := extractor.NewByteCharBoundaryUnits(encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &unitsSplitter{
+		size:  size,
+		units: units,
+	}, nil
+}
+
+func (s *byteSplitter) Split(input io.Reader, create CreateFunc) (int, error) {
+
+	reader := bufio.NewReader(input)
+	chunks := newChunkWriter(create, nil)
+	defer chunks.abort()
+
+	for {
+		// 終端に達していたら、空の出力先は作らずに終了
+		if _, err := reader.Peek(1); err == io.EOF {
+			break
+		} else if err != nil {
+			return chunks.completed, err
+		}
+
+		writer, err := chunks.current()
+		if err != nil {
+			return chunks.completed, err
+		}
+		if _, err := io.CopyN(writer, reader, s.size); err != nil && err != io.EOF {
+			return chunks.completed, err
+		}
+		if err := chunks.next(); err != nil {
+			return chunks.completed, err
+		}
+	}
+
+	return chunks.close()
+}
@@ -0,0 +1,134 @@
+package splitter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestNewByteSplitter(t *testing.T) {
+
+	tests := []struct {
+		size     int64
+		expected [][]byte
+	}{
+		{size: 3, expected: [][]byte{{0x01, 0x02, 0x03}, {0x04, 0x05, 0x06}, {0x07}}},
+		{size: 7, expected: [][]byte{{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07}}},
+		{size: 8, expected: [][]byte{{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07}}},
+		{size: 1, expected: [][]byte{{0x01}, {0x02}, {0x03}, {0x04}, {0x05}, {0x06}, {0x07}}},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		splitter, err := NewByteSplitter(tt.size)
+		require.NoError(t, err)
+
+		chunks := &memoryChunks{}
+
+		// ACT
+		num, err := splitter.Split(bytes.NewReader([]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07}), chunks.create)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, len(tt.expected), num)
+		assert.Equal(t, tt.expected, chunks.contents(t))
+	}
+}
+
+func TestNewByteSplitter_Empty(t *testing.T) {
+
+	// ARRANGE
+	splitter, err := NewByteSplitter(3)
+	require.NoError(t, err)
+
+	chunks := &memoryChunks{}
+
+	// ACT
+	num, err := splitter.Split(bytes.NewReader([]byte{}), chunks.create)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, 0, num)
+	assert.Equal(t, [][]byte{}, chunks.contents(t))
+}
+
+func TestNewByteSplitter_InvalidSize(t *testing.T) {
+
+	// ACT
+	_, err := NewByteSplitter(0)
+
+	// ASSERT
+	assert.EqualError(t, err, "number must be greater than 0")
+}
+
+func TestNewByteCharBoundarySplitter(t *testing.T) {
+
+	// ARRANGE
+	splitter, err := NewByteCharBoundarySplitter(4, "UTF-8")
+	require.NoError(t, err)
+
+	chunks := &memoryChunks{}
+
+	// ACT
+	num, err := splitter.Split(bytes.NewReader([]byte("aあいb")), chunks.create)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, 2, num)
+	assert.Equal(t, [][]byte{[]byte("aあ"), []byte("いb")}, chunks.contents(t))
+}
+
+func TestNewByteCharBoundarySplitter_SJIS(t *testing.T) {
+
+	// ARRANGE
+	splitter, err := NewByteCharBoundarySplitter(3, "sjis")
+	require.NoError(t, err)
+
+	chunks := &memoryChunks{}
+
+	// ACT
+	num, err := splitter.Split(bytes.NewReader(test.StringToByte(t, "あいaう", japanese.ShiftJIS)), chunks.create)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, 3, num)
+	assert.Equal(
+		t,
+		[][]byte{
+			test.StringToByte(t, "あ", japanese.ShiftJIS),
+			test.StringToByte(t, "いa", japanese.ShiftJIS),
+			test.StringToByte(t, "う", japanese.ShiftJIS),
+		},
+		chunks.contents(t))
+}
+
+func TestNewByteCharBoundarySplitter_LargerThanSize(t *testing.T) {
+
+	// ARRANGE
+	splitter, err := NewByteCharBoundarySplitter(2, "UTF-8")
+	require.NoError(t, err)
+
+	chunks := &memoryChunks{}
+
+	// ACT
+	num, err := splitter.Split(bytes.NewReader([]byte("aあb")), chunks.create)
+
+	// ASSERT
+	require.NoError(t, err)
+	// 1文字で指定バイト数を超える場合は、その文字だけで分割
+	assert.Equal(t, 3, num)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("あ"), []byte("b")}, chunks.contents(t))
+}
+
+func TestNewByteCharBoundarySplitter_InvalidEncoding(t *testing.T) {
+
+	// ACT
+	_, err := NewByteCharBoundarySplitter(3, "utf")
+
+	// ASSERT
+	assert.EqualError(t, err, "utf is invalid: htmlindex: invalid encoding name")
+}
@@ -0,0 +1,22 @@
+package splitter
+
+import (
+	"github.com/onozaty/filep/extract/extractor"
+)
+
+func NewCharSplitter(size int64, encodingName string) (Splitter, error) {
+
+	if err := validateSize(size); err != nil {
+		return nil, err
+	}
+
+	units, err := extractor.NewCharUnits(extractor.CharUnitRune, encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &unitsSplitter{
+		size:  size,
+		units: units,
+	}, nil
+}
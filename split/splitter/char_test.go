@@ -0,0 +1,62 @@
+package splitter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestNewCharSplitter(t *testing.T) {
+
+	// ARRANGE
+	splitter, err := NewCharSplitter(3, "UTF-8")
+	require.NoError(t, err)
+
+	chunks := &memoryChunks{}
+
+	// ACT
+	num, err := splitter.Split(strings.NewReader("あいうえお12"), chunks.create)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, 3, num)
+	assert.Equal(t, [][]byte{[]byte("あいう"), []byte("えお1"), []byte("2")}, chunks.contents(t))
+}
+
+func TestNewCharSplitter_ISO2022JP(t *testing.T) {
+
+	// ARRANGE
+	splitter, err := NewCharSplitter(2, "iso-2022-jp")
+	require.NoError(t, err)
+
+	chunks := &memoryChunks{}
+
+	// ACT
+	num, err := splitter.Split(bytes.NewReader(test.StringToByte(t, "あいうえ", japanese.ISO2022JP)), chunks.create)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, 2, num)
+	// それぞれ単独で読めるように、エスケープシーケンスが付与される
+	assert.Equal(
+		t,
+		[][]byte{
+			test.StringToByte(t, "あい", japanese.ISO2022JP),
+			test.StringToByte(t, "うえ", japanese.ISO2022JP),
+		},
+		chunks.contents(t))
+}
+
+func TestNewCharSplitter_InvalidEncoding(t *testing.T) {
+
+	// ACT
+	_, err := NewCharSplitter(3, "utf")
+
+	// ASSERT
+	assert.EqualError(t, err, "utf is invalid: htmlindex: invalid encoding name")
+}
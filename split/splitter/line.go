@@ -0,0 +1,23 @@
+package splitter
+
+import (
+	"github.com/onozaty/filep/extract/extractor"
+)
+
+// LFを改行として、指定行数ごとに分割します。
+func NewLineSplitter(size int64, encodingName string) (Splitter, error) {
+
+	if err := validateSize(size); err != nil {
+		return nil, err
+	}
+
+	units, err := extractor.NewLineUnits(extractor.NewlineLF, encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &unitsSplitter{
+		size:  size,
+		units: units,
+	}, nil
+}
@@ -0,0 +1,79 @@
+package splitter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestNewLineSplitter(t *testing.T) {
+
+	tests := []struct {
+		size     int64
+		expected []string
+	}{
+		{size: 2, expected: []string{"1\n2\r\n", "\n4\n", "5"}},
+		{size: 5, expected: []string{"1\n2\r\n\n4\n5"}},
+		{size: 1, expected: []string{"1\n", "2\r\n", "\n", "4\n", "5"}},
+	}
+
+	for _, tt := range tests {
+		// ARRANGE
+		splitter, err := NewLineSplitter(tt.size, "UTF-8")
+		require.NoError(t, err)
+
+		chunks := &memoryChunks{}
+
+		// ACT
+		num, err := splitter.Split(strings.NewReader("1\n2\r\n\n4\n5"), chunks.create)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, len(tt.expected), num)
+
+		expected := [][]byte{}
+		for _, s := range tt.expected {
+			expected = append(expected, []byte(s))
+		}
+		assert.Equal(t, expected, chunks.contents(t))
+	}
+}
+
+func TestNewLineSplitter_UTF16(t *testing.T) {
+
+	// ARRANGE
+	encoding := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+
+	splitter, err := NewLineSplitter(1, "utf-16le")
+	require.NoError(t, err)
+
+	chunks := &memoryChunks{}
+
+	// ACT
+	num, err := splitter.Split(bytes.NewReader(test.StringToByte(t, "あ\nਊ\n", encoding)), chunks.create)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, 2, num)
+	assert.Equal(
+		t,
+		[][]byte{
+			test.StringToByte(t, "あ\n", encoding),
+			test.StringToByte(t, "ਊ\n", encoding),
+		},
+		chunks.contents(t))
+}
+
+func TestNewLineSplitter_InvalidSize(t *testing.T) {
+
+	// ACT
+	_, err := NewLineSplitter(-1, "UTF-8")
+
+	// ASSERT
+	assert.EqualError(t, err, "number must be greater than 0")
+}
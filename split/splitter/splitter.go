@@ -0,0 +1,170 @@
+package splitter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/onozaty/filep/extract/extractor"
+)
+
+type Splitter interface {
+	// 入力を分割して出力し、分割したファイルの数を返します。
+	// エラーの場合は、書き込みが完了したファイルの数を返します。
+	Split(input io.Reader, create CreateFunc) (int, error)
+}
+
+// 分割したnum番目(1から)の出力先を作成します。
+type CreateFunc func(num int) (io.WriteCloser, error)
+
+func validateSize(size int64) error {
+
+	if size <= 0 {
+		return fmt.Errorf("number must be greater than 0")
+	}
+
+	return nil
+}
+
+// 大きさを持つ単位(文字、行など)ごとに、指定された大きさ以下で分割します。
+type unitsSplitter struct {
+	size  int64
+	units extractor.SizedUnits
+}
+
+func (s *unitsSplitter) Split(input io.Reader, create CreateFunc) (int, error) {
+
+	next := s.units.Reader(input)
+	chunks := newChunkWriter(create, s.units.Writer)
+	defer chunks.abort()
+
+	written := int64(0)
+	for {
+		value, size, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return chunks.completed, err
+		}
+
+		// 単位を加えると超えてしまう場合は、その前で分割
+		// (1つの単位で超える場合は、その単位だけで1つの出力先とする)
+		if written > 0 && written+size > s.size {
+			if err := chunks.next(); err != nil {
+				return chunks.completed, err
+			}
+			written = 0
+		}
+
+		writer, err := chunks.current()
+		if err != nil {
+			return chunks.completed, err
+		}
+		if _, err := writer.WriteString(value); err != nil {
+			return chunks.completed, err
+		}
+		written += size
+	}
+
+	return chunks.close()
+}
+
+// 分割した出力先を、必要になった時点で順に作成しながら書き込むためのものです。
+type chunkWriter struct {
+	create CreateFunc
+	// 文字や行で分割する場合に、出力先ごとにエンコードするWriterを作成(バイトで分割する場合はnil)
+	newEncoder func(output io.Writer) io.WriteCloser
+	// 作成した出力先の数
+	num int
+	// 書き込みが完了した出力先の数
+	completed int
+	output    io.WriteCloser
+	encoder   io.WriteCloser
+	writer    *bufio.Writer
+}
+
+func newChunkWriter(create CreateFunc, newEncoder func(output io.Writer) io.WriteCloser) *chunkWriter {
+
+	return &chunkWriter{
+		create:     create,
+		newEncoder: newEncoder,
+	}
+}
+
+// 現在の出力先を返します。無い場合は次の出力先を作成します。
+func (c *chunkWriter) current() (*bufio.Writer, error) {
+
+	if c.writer != nil {
+		return c.writer, nil
+	}
+
+	output, err := c.create(c.num + 1)
+	if err != nil {
+		return nil, err
+	}
+	c.num++
+	c.output = output
+
+	if c.newEncoder != nil {
+		// エンコーダは出力先ごとに分けて、それぞれ単独で読める内容にする
+		c.encoder = c.newEncoder(output)
+		c.writer = bufio.NewWriter(c.encoder)
+	} else {
+		c.writer = bufio.NewWriter(output)
+	}
+
+	return c.writer, nil
+}
+
+// 現在の出力先を閉じます。次に書き込む際には、新たな出力先が作成されます。
+func (c *chunkWriter) next() error {
+
+	if c.writer == nil {
+		return nil
+	}
+
+	err := c.writer.Flush()
+	if err == nil && c.encoder != nil {
+		err = c.encoder.Close()
+	}
+	if closeErr := c.output.Close(); err == nil {
+		err = closeErr
+	}
+
+	c.writer = nil
+	c.encoder = nil
+	c.output = nil
+
+	if err != nil {
+		return err
+	}
+
+	c.completed++
+	return nil
+}
+
+// 書き込み途中の出力先があれば閉じて、作成した出力先の数を返します。
+func (c *chunkWriter) close() (int, error) {
+
+	if err := c.next(); err != nil {
+		return c.completed, err
+	}
+
+	return c.completed, nil
+}
+
+// エラーで中断した場合に、書き込み途中の出力先が残っていれば閉じます。
+// 書き込み途中の内容は出力しないため、完了した出力先には数えません。
+func (c *chunkWriter) abort() {
+
+	if c.output == nil {
+		return
+	}
+
+	c.output.Close()
+
+	c.writer = nil
+	c.encoder = nil
+	c.output = nil
+}
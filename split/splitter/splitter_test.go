@@ -0,0 +1,76 @@
+package splitter
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplit_ReadError(t *testing.T) {
+
+	newSplitters := map[string]func() (Splitter, error){
+		"byte":               func() (Splitter, error) { return NewByteSplitter(4) },
+		"byte char boundary": func() (Splitter, error) { return NewByteCharBoundarySplitter(4, "UTF-8") },
+		"char":               func() (Splitter, error) { return NewCharSplitter(4, "UTF-8") },
+		"line":               func() (Splitter, error) { return NewLineSplitter(2, "UTF-8") },
+	}
+
+	for name, newSplitter := range newSplitters {
+		// ARRANGE
+		splitter, err := newSplitter()
+		require.NoError(t, err)
+
+		chunks := &memoryChunks{}
+		input := io.MultiReader(strings.NewReader("1\n2\n3\n"), iotest.ErrReader(errors.New("read error")))
+
+		// ACT
+		num, err := splitter.Split(input, chunks.create)
+
+		// ASSERT
+		assert.EqualError(t, err, "read error", name)
+		// 書き込み途中の出力先も閉じられ、完了した出力先の数だけが返る
+		assert.Equal(t, 1, num, name)
+		assert.Len(t, chunks.chunks, 2, name)
+		assert.Equal(t, []byte("1\n2\n"), chunks.contents(t)[0], name)
+	}
+}
+
+// 分割した内容をメモリ上に保持するための出力先です。
+type memoryChunks struct {
+	chunks []*memoryChunk
+}
+
+type memoryChunk struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *memoryChunk) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (m *memoryChunks) create(num int) (io.WriteCloser, error) {
+	chunk := &memoryChunk{}
+	m.chunks = append(m.chunks, chunk)
+	return chunk, nil
+}
+
+func (m *memoryChunks) contents(t *testing.T) [][]byte {
+	t.Helper()
+
+	contents := [][]byte{}
+	for i, chunk := range m.chunks {
+		if !chunk.closed {
+			t.Fatalf("chunk %d is not closed", i+1)
+		}
+		contents = append(contents, chunk.Bytes())
+	}
+	return contents
+}
@@ -64,3 +64,21 @@ func StringToByte(t *testing.T, str string, enc encoding.Encoding) []byte {
 
 	return encoded
 }
+
+func OpenFile(t *testing.T, name string) *os.File {
+
+	file, err := os.Open(name)
+	require.NoError(t, err)
+	t.Cleanup(func() { file.Close() })
+
+	return file
+}
+
+func CreateFile(t *testing.T, name string) *os.File {
+
+	file, err := os.Create(name)
+	require.NoError(t, err)
+	t.Cleanup(func() { file.Close() })
+
+	return file
+}
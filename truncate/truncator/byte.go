@@ -1,23 +1,58 @@
 package truncator
 
 import (
+	"io"
+
 	"github.com/onozaty/filep/extract/extractor"
 )
 
-func NewByteTruncator(byteNum int64) (*Truncator, error) {
+func NewByteTruncator(byteNum int64, keep Keep) (*Truncator, error) {
 
 	if byteNum == 0 {
 		// 0を指定された場合、空ファイルを作るだけ
 		return newEmptyTruncator()
 	}
 
-	// 1バイト目から取り出すことで切り捨てと同じ扱いに
-	extractor, err := extractor.NewByteExtractor(1, byteNum)
+	// 残す部分を取り出すことで切り捨てと同じ扱いに
+	start, end := keep.position(byteNum)
+	extractor, err := extractor.NewByteExtractor(start, end)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Truncator{
+	truncator := &Truncator{
 		extractor: extractor,
-	}, nil
+	}
+	if keep == KeepHead {
+		// 先頭から指定バイト数の位置で切り詰めれば良いので、内容を読み込む必要は無い
+		truncator.headSize = func(input File) (int64, bool, error) {
+			size, err := input.Seek(0, io.SeekEnd)
+			if err != nil {
+				return 0, false, err
+			}
+			if _, err := input.Seek(0, io.SeekStart); err != nil {
+				return 0, false, err
+			}
+			return min(byteNum, size), true, nil
+		}
+	}
+
+	return truncator, nil
+}
+
+// 文字の途中で切り捨てないように、指定バイト数以下に収まる文字までとします。
+func NewByteCharBoundaryTruncator(byteNum int64, keep Keep, encodingName string) (*Truncator, error) {
+
+	if byteNum == 0 {
+		// 0を指定された場合、空ファイルを作るだけ
+		return newEmptyTruncator()
+	}
+
+	start, end := keep.position(byteNum)
+	extractor, err := extractor.NewByteCharBoundaryExtractor(start, end, encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTruncator(extractor, keep), nil
 }
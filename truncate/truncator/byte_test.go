@@ -1,13 +1,13 @@
 package truncator
 
 import (
-	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/onozaty/filep/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
 )
 
 func TestNewByteTruncator(t *testing.T) {
@@ -22,9 +22,9 @@ func TestNewByteTruncator(t *testing.T) {
 		output := filepath.Join(d, "output10")
 
 		// ACT
-		truncator, err := NewByteTruncator(10)
+		truncator, err := NewByteTruncator(10, KeepHead)
 		require.NoError(t, err)
-		err = truncator.Truncate(input, output)
+		err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -37,9 +37,9 @@ func TestNewByteTruncator(t *testing.T) {
 		output := filepath.Join(d, "output9")
 
 		// ACT
-		truncator, err := NewByteTruncator(9)
+		truncator, err := NewByteTruncator(9, KeepHead)
 		require.NoError(t, err)
-		err = truncator.Truncate(input, output)
+		err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -52,9 +52,9 @@ func TestNewByteTruncator(t *testing.T) {
 		output := filepath.Join(d, "output11")
 
 		// ACT
-		truncator, err := NewByteTruncator(11)
+		truncator, err := NewByteTruncator(11, KeepHead)
 		require.NoError(t, err)
-		err = truncator.Truncate(input, output)
+		err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -67,9 +67,9 @@ func TestNewByteTruncator(t *testing.T) {
 		output := filepath.Join(d, "output0")
 
 		// ACT
-		truncator, err := NewByteTruncator(0)
+		truncator, err := NewByteTruncator(0, KeepHead)
 		require.NoError(t, err)
-		err = truncator.Truncate(input, output)
+		err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -80,42 +80,116 @@ func TestNewByteTruncator(t *testing.T) {
 	}
 }
 
-func TestNewByteTruncator_InputFileNotFound(t *testing.T) {
+func TestNewByteCharBoundaryTruncator(t *testing.T) {
 
 	// ARRANGE
 	d := t.TempDir()
 
-	input := filepath.Join(d, "xxxx")
+	input := test.CreateFileWriteString(
+		t, d, "input", "aあいb")
+
+	{
+		output := filepath.Join(d, "output7")
+
+		// ACT
+		truncator, err := NewByteCharBoundaryTruncator(7, KeepHead, "UTF-8")
+		require.NoError(t, err)
+		err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "aあい", test.ReadString(t, output))
+	}
+	{
+		output := filepath.Join(d, "output6")
+
+		// ACT
+		truncator, err := NewByteCharBoundaryTruncator(6, KeepHead, "UTF-8")
+		require.NoError(t, err)
+		err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "aあ", test.ReadString(t, output))
+	}
+	{
+		output := filepath.Join(d, "output0")
+
+		// ACT
+		truncator, err := NewByteCharBoundaryTruncator(0, KeepHead, "UTF-8")
+		require.NoError(t, err)
+		err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "", test.ReadString(t, output))
+	}
+}
+
+func TestNewByteCharBoundaryTruncator_SJIS(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteBytes(
+		t, d, "input", test.StringToByte(t, "あいうえお", japanese.ShiftJIS))
 	output := filepath.Join(d, "output")
 
 	// ACT
-	truncator, err := NewByteTruncator(10)
+	truncator, err := NewByteCharBoundaryTruncator(5, KeepHead, "sjis")
 	require.NoError(t, err)
-	err = truncator.Truncate(input, output)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 	// ASSERT
-	require.Error(t, err)
-	pathErr := err.(*os.PathError)
-	assert.Equal(t, input, pathErr.Path)
-	assert.Equal(t, "open", pathErr.Op)
+	require.NoError(t, err)
+	assert.Equal(t, "あい", test.ByteToString(t, test.ReadBytes(t, output), japanese.ShiftJIS))
+}
+
+func TestNewByteTruncator_KeepTail(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "0123456789")
+
+	tests := []struct {
+		byteNum  int64
+		expected string
+	}{
+		{byteNum: 3, expected: "789"},
+		{byteNum: 10, expected: "0123456789"},
+		{byteNum: 11, expected: "0123456789"},
+		{byteNum: 0, expected: ""},
+	}
+
+	for _, tt := range tests {
+		output := filepath.Join(d, "output")
+
+		// ACT
+		truncator, err := NewByteTruncator(tt.byteNum, KeepTail)
+		require.NoError(t, err)
+		err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, test.ReadString(t, output), "byteNum=%d", tt.byteNum)
+	}
 }
 
-func TestNewByteTruncator_OutputFileNotFound(t *testing.T) {
+func TestNewByteCharBoundaryTruncator_KeepTail(t *testing.T) {
 
 	// ARRANGE
 	d := t.TempDir()
 
-	input := test.CreateFileWriteBytes(t, d, "input", []byte{})
-	output := filepath.Join(d, "non", "output")
+	input := test.CreateFileWriteString(t, d, "input", "あいうえお")
+	output := filepath.Join(d, "output")
 
 	// ACT
-	truncator, err := NewByteTruncator(10)
+	truncator, err := NewByteCharBoundaryTruncator(8, KeepTail, "UTF-8")
 	require.NoError(t, err)
-	err = truncator.Truncate(input, output)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 	// ASSERT
-	require.Error(t, err)
-	pathErr := err.(*os.PathError)
-	assert.Equal(t, output, pathErr.Path)
-	assert.Equal(t, "open", pathErr.Op)
+	require.NoError(t, err)
+	assert.Equal(t, "えお", test.ReadString(t, output))
 }
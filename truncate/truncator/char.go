@@ -2,20 +2,19 @@ package truncator
 
 import "github.com/onozaty/filep/extract/extractor"
 
-func NewCharTruncator(charNum int64, encodingName string) (*Truncator, error) {
+func NewCharTruncator(charNum int64, keep Keep, unit extractor.CharUnit, encodingName string) (*Truncator, error) {
 
 	if charNum == 0 {
 		// 0を指定された場合、空ファイルを作るだけ
 		return newEmptyTruncator()
 	}
 
-	// 1文字目から取り出すことで切り捨てと同じ扱いに
-	extractor, err := extractor.NewCharExtractor(1, charNum, encodingName)
+	// 残す部分を取り出すことで切り捨てと同じ扱いに
+	start, end := keep.position(charNum)
+	extractor, err := extractor.NewCharExtractor(start, end, unit, encodingName)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Truncator{
-		extractor: extractor,
-	}, nil
+	return newTruncator(extractor, keep), nil
 }
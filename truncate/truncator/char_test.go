@@ -1,10 +1,10 @@
 package truncator
 
 import (
-	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/onozaty/filep/extract/extractor"
 	"github.com/onozaty/filep/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -23,8 +23,8 @@ func TestNewCharTruncator(t *testing.T) {
 		output := filepath.Join(d, "output10")
 
 		// ACT
-		truncator, _ := NewCharTruncator(10, "UTF-8")
-		err := truncator.Truncate(input, output)
+		truncator, _ := NewCharTruncator(10, KeepHead, extractor.CharUnitRune, "UTF-8")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -37,8 +37,8 @@ func TestNewCharTruncator(t *testing.T) {
 		output := filepath.Join(d, "output9")
 
 		// ACT
-		truncator, _ := NewCharTruncator(9, "UTF-8")
-		err := truncator.Truncate(input, output)
+		truncator, _ := NewCharTruncator(9, KeepHead, extractor.CharUnitRune, "UTF-8")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -51,8 +51,8 @@ func TestNewCharTruncator(t *testing.T) {
 		output := filepath.Join(d, "output11")
 
 		// ACT
-		truncator, _ := NewCharTruncator(11, "UTF-8")
-		err := truncator.Truncate(input, output)
+		truncator, _ := NewCharTruncator(11, KeepHead, extractor.CharUnitRune, "UTF-8")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -63,10 +63,10 @@ func TestNewCharTruncator(t *testing.T) {
 	}
 	{
 		output := filepath.Join(d, "output0")
-		truncator, _ := NewCharTruncator(0, "UTF-8")
+		truncator, _ := NewCharTruncator(0, KeepHead, extractor.CharUnitRune, "UTF-8")
 
 		// ACT
-		err := truncator.Truncate(input, output)
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -89,8 +89,8 @@ func TestNewCharTruncator_SJIS(t *testing.T) {
 		output := filepath.Join(d, "output10")
 
 		// ACT
-		truncator, _ := NewCharTruncator(10, "SJIS")
-		err := truncator.Truncate(input, output)
+		truncator, _ := NewCharTruncator(10, KeepHead, extractor.CharUnitRune, "SJIS")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -101,10 +101,10 @@ func TestNewCharTruncator_SJIS(t *testing.T) {
 	}
 	{
 		output := filepath.Join(d, "output9")
-		truncator, _ := NewCharTruncator(9, "SJIS")
+		truncator, _ := NewCharTruncator(9, KeepHead, extractor.CharUnitRune, "SJIS")
 
 		// ACT
-		err := truncator.Truncate(input, output)
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -117,8 +117,8 @@ func TestNewCharTruncator_SJIS(t *testing.T) {
 		output := filepath.Join(d, "output11")
 
 		// ACT
-		truncator, _ := NewCharTruncator(11, "SJIS")
-		err := truncator.Truncate(input, output)
+		truncator, _ := NewCharTruncator(11, KeepHead, extractor.CharUnitRune, "SJIS")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -129,10 +129,10 @@ func TestNewCharTruncator_SJIS(t *testing.T) {
 	}
 	{
 		output := filepath.Join(d, "output0")
-		truncator, _ := NewCharTruncator(0, "SJIS")
+		truncator, _ := NewCharTruncator(0, KeepHead, extractor.CharUnitRune, "SJIS")
 
 		// ACT
-		err := truncator.Truncate(input, output)
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -143,50 +143,49 @@ func TestNewCharTruncator_SJIS(t *testing.T) {
 	}
 }
 
-func TestNewCharTruncator_InputFileNotFound(t *testing.T) {
-
-	// ARRANGE
-	d := t.TempDir()
-
-	input := filepath.Join(d, "xxxx")
-	output := filepath.Join(d, "output")
+func TestNewCharTruncator_InvalidEncoding(t *testing.T) {
 
 	// ACT
-	truncator, _ := NewCharTruncator(9, "UTF-8")
-	err := truncator.Truncate(input, output)
+	_, err := NewCharTruncator(9, KeepHead, extractor.CharUnitRune, "utf")
 
 	// ASSERT
 	require.Error(t, err)
-	pathErr := err.(*os.PathError)
-	assert.Equal(t, input, pathErr.Path)
-	assert.Equal(t, "open", pathErr.Op)
+	assert.EqualError(t, err, "utf is invalid: htmlindex: invalid encoding name")
 }
 
-func TestNewCharTruncator_OutputFileNotFound(t *testing.T) {
+func TestNewCharTruncator_Grapheme(t *testing.T) {
 
 	// ARRANGE
 	d := t.TempDir()
 
-	input := test.CreateFileWriteString(t, d, "input", "")
-	output := filepath.Join(d, "non", "output")
+	input := test.CreateFileWriteString(
+		t, d, "input", "か\u3099👨\u200d👩\u200d👧🇯🇵")
+	output := filepath.Join(d, "output")
 
 	// ACT
-	truncator, _ := NewCharTruncator(9, "UTF-8")
-	err := truncator.Truncate(input, output)
+	truncator, err := NewCharTruncator(2, KeepHead, extractor.CharUnitGrapheme, "UTF-8")
+	require.NoError(t, err)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 	// ASSERT
-	require.Error(t, err)
-	pathErr := err.(*os.PathError)
-	assert.Equal(t, output, pathErr.Path)
-	assert.Equal(t, "open", pathErr.Op)
+	require.NoError(t, err)
+	assert.Equal(t, "か\u3099👨\u200d👩\u200d👧", test.ReadString(t, output))
 }
 
-func TestNewCharTruncator_InvalidEncoding(t *testing.T) {
+func TestNewCharTruncator_KeepTail(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "あいうえお12345")
+	output := filepath.Join(d, "output")
 
 	// ACT
-	_, err := NewCharTruncator(9, "utf")
+	truncator, err := NewCharTruncator(7, KeepTail, extractor.CharUnitRune, "UTF-8")
+	require.NoError(t, err)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 	// ASSERT
-	require.Error(t, err)
-	assert.EqualError(t, err, "utf is invalid: htmlindex: invalid encoding name")
+	require.NoError(t, err)
+	assert.Equal(t, "えお12345", test.ReadString(t, output))
 }
@@ -0,0 +1,19 @@
+package truncator
+
+import (
+	"github.com/onozaty/filep/extract/extractor"
+)
+
+// 単位ごとに先頭と末尾を残し、その間を切り捨てます。
+// 切り捨てた場合は、間にマーカー(%dは切り捨てた大きさに置き換え)を出力します。行単位の場合、マーカーは1行として出力します。
+func NewHeadTailTruncator(head int64, tail int64, marker string, units extractor.SizedUnits) (*Truncator, error) {
+
+	extractor, err := extractor.NewHeadTailExtractor(head, tail, marker, units)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Truncator{
+		extractor: extractor,
+	}, nil
+}
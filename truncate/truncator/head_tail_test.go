@@ -0,0 +1,111 @@
+package truncator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/onozaty/filep/extract/extractor"
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLineHeadTailTruncator(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "1\n2\n3\n4\n5\n")
+	output := filepath.Join(d, "output")
+
+	// ACT
+	units, err := extractor.NewLineUnits(extractor.NewlineLF, "UTF-8")
+	require.NoError(t, err)
+	truncator, err := NewHeadTailTruncator(1, 2, "... [%d lines omitted] ...", units)
+	require.NoError(t, err)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "1\n... [2 lines omitted] ...\n4\n5\n", test.ReadString(t, output))
+}
+
+func TestNewByteHeadTailTruncator(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "0123456789")
+	output := filepath.Join(d, "output")
+
+	// ACT
+	units, err := extractor.NewByteUnits("UTF-8")
+	require.NoError(t, err)
+	truncator, err := NewHeadTailTruncator(2, 2, "[%d]", units)
+	require.NoError(t, err)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "01[6]89", test.ReadString(t, output))
+}
+
+func TestNewByteCharBoundaryHeadTailTruncator(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "あいうえお")
+	output := filepath.Join(d, "output")
+
+	// ACT
+	units, err := extractor.NewByteCharBoundaryUnits("UTF-8")
+	require.NoError(t, err)
+	truncator, err := NewHeadTailTruncator(4, 7, "[%d]", units)
+	require.NoError(t, err)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "あ[6]えお", test.ReadString(t, output))
+}
+
+func TestNewCharHeadTailTruncator(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "あいうえお")
+	output := filepath.Join(d, "output")
+
+	// ACT
+	units, err := extractor.NewCharUnits(extractor.CharUnitRune, "UTF-8")
+	require.NoError(t, err)
+	truncator, err := NewHeadTailTruncator(1, 1, "…", units)
+	require.NoError(t, err)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "あ…お", test.ReadString(t, output))
+}
+
+func TestNewWidthHeadTailTruncator(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "あいうえお")
+	output := filepath.Join(d, "output")
+
+	// ACT
+	units, err := extractor.NewWidthUnits(1, "UTF-8")
+	require.NoError(t, err)
+	truncator, err := NewHeadTailTruncator(4, 2, "..", units)
+	require.NoError(t, err)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "あい..お", test.ReadString(t, output))
+}
@@ -0,0 +1,24 @@
+package truncator
+
+// 切り捨てる際に残す部分です。
+type Keep int
+
+const (
+	// 先頭から残す
+	KeepHead Keep = iota
+	// 末尾から残す
+	KeepTail
+	// 先頭と末尾を残す(HeadTailのTruncatorで、それぞれの大きさを指定)
+	KeepHeadTail
+)
+
+// 残す部分の開始位置と終了位置を返します。
+// 末尾から残す場合は負の位置(末尾から数えた位置)とすることで、ファイルの場合は末尾から読み込みます。
+func (k Keep) position(num int64) (int64, int64) {
+
+	if k == KeepTail {
+		return -num, -1
+	}
+
+	return 1, num
+}
@@ -4,20 +4,19 @@ import (
 	"github.com/onozaty/filep/extract/extractor"
 )
 
-func NewLineTruncator(lineNum int64, encodingName string) (*Truncator, error) {
+func NewLineTruncator(lineNum int64, keep Keep, newline extractor.Newline, encodingName string) (*Truncator, error) {
 
 	if lineNum == 0 {
 		// 0を指定された場合、空ファイルを作るだけ
 		return newEmptyTruncator()
 	}
 
-	// 1行目から取り出すことで切り捨てと同じ扱いに
-	extractor, err := extractor.NewLineExtractor(1, lineNum, encodingName)
+	// 残す部分を取り出すことで切り捨てと同じ扱いに
+	start, end := keep.position(lineNum)
+	extractor, err := extractor.NewLineExtractor(start, end, newline, encodingName)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Truncator{
-		extractor: extractor,
-	}, nil
+	return newTruncator(extractor, keep), nil
 }
@@ -1,10 +1,10 @@
 package truncator
 
 import (
-	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/onozaty/filep/extract/extractor"
 	"github.com/onozaty/filep/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -23,8 +23,8 @@ func TestNewLineTruncator(t *testing.T) {
 		output := filepath.Join(d, "output10")
 
 		// ACT
-		truncator, _ := NewLineTruncator(10, "UTF-8")
-		err := truncator.Truncate(input, output)
+		truncator, _ := NewLineTruncator(10, KeepHead, extractor.NewlineLF, "UTF-8")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -37,8 +37,8 @@ func TestNewLineTruncator(t *testing.T) {
 		output := filepath.Join(d, "output9")
 
 		// ACT
-		truncator, _ := NewLineTruncator(9, "UTF-8")
-		err := truncator.Truncate(input, output)
+		truncator, _ := NewLineTruncator(9, KeepHead, extractor.NewlineLF, "UTF-8")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -51,8 +51,8 @@ func TestNewLineTruncator(t *testing.T) {
 		output := filepath.Join(d, "output11")
 
 		// ACT
-		truncator, _ := NewLineTruncator(11, "UTF-8")
-		err := truncator.Truncate(input, output)
+		truncator, _ := NewLineTruncator(11, KeepHead, extractor.NewlineLF, "UTF-8")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -65,8 +65,8 @@ func TestNewLineTruncator(t *testing.T) {
 		output := filepath.Join(d, "output0")
 
 		// ACT
-		truncator, _ := NewLineTruncator(0, "UTF-8")
-		err := truncator.Truncate(input, output)
+		truncator, _ := NewLineTruncator(0, KeepHead, extractor.NewlineLF, "UTF-8")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -89,8 +89,8 @@ func TestNewLineTruncator_改行無しで終了(t *testing.T) {
 		output := filepath.Join(d, "output4")
 
 		// ACT
-		truncator, _ := NewLineTruncator(4, "UTF-8")
-		err := truncator.Truncate(input, output)
+		truncator, _ := NewLineTruncator(4, KeepHead, extractor.NewlineLF, "UTF-8")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -103,8 +103,8 @@ func TestNewLineTruncator_改行無しで終了(t *testing.T) {
 		output := filepath.Join(d, "output3")
 
 		// ACT
-		truncator, _ := NewLineTruncator(3, "UTF-8")
-		err := truncator.Truncate(input, output)
+		truncator, _ := NewLineTruncator(3, KeepHead, extractor.NewlineLF, "UTF-8")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -117,8 +117,8 @@ func TestNewLineTruncator_改行無しで終了(t *testing.T) {
 		output := filepath.Join(d, "output2")
 
 		// ACT
-		truncator, _ := NewLineTruncator(2, "UTF-8")
-		err := truncator.Truncate(input, output)
+		truncator, _ := NewLineTruncator(2, KeepHead, extractor.NewlineLF, "UTF-8")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -131,8 +131,8 @@ func TestNewLineTruncator_改行無しで終了(t *testing.T) {
 		output := filepath.Join(d, "output1")
 
 		// ACT
-		truncator, _ := NewLineTruncator(1, "UTF-8")
-		err := truncator.Truncate(input, output)
+		truncator, _ := NewLineTruncator(1, KeepHead, extractor.NewlineLF, "UTF-8")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -145,8 +145,8 @@ func TestNewLineTruncator_改行無しで終了(t *testing.T) {
 		output := filepath.Join(d, "output0")
 
 		// ACT
-		truncator, _ := NewLineTruncator(0, "UTF-8")
-		err := truncator.Truncate(input, output)
+		truncator, _ := NewLineTruncator(0, KeepHead, extractor.NewlineLF, "UTF-8")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -169,8 +169,8 @@ func TestNewLineTruncator_SJIS(t *testing.T) {
 		output := filepath.Join(d, "output3")
 
 		// ACT
-		truncator, _ := NewLineTruncator(3, "SJIS")
-		err := truncator.Truncate(input, output)
+		truncator, _ := NewLineTruncator(3, KeepHead, extractor.NewlineLF, "SJIS")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -181,10 +181,10 @@ func TestNewLineTruncator_SJIS(t *testing.T) {
 	}
 	{
 		output := filepath.Join(d, "output2")
-		truncator, _ := NewLineTruncator(2, "SJIS")
+		truncator, _ := NewLineTruncator(2, KeepHead, extractor.NewlineLF, "SJIS")
 
 		// ACT
-		err := truncator.Truncate(input, output)
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -197,8 +197,8 @@ func TestNewLineTruncator_SJIS(t *testing.T) {
 		output := filepath.Join(d, "output1")
 
 		// ACT
-		truncator, _ := NewLineTruncator(1, "SJIS")
-		err := truncator.Truncate(input, output)
+		truncator, _ := NewLineTruncator(1, KeepHead, extractor.NewlineLF, "SJIS")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -209,10 +209,10 @@ func TestNewLineTruncator_SJIS(t *testing.T) {
 	}
 	{
 		output := filepath.Join(d, "output0")
-		truncator, _ := NewLineTruncator(0, "SJIS")
+		truncator, _ := NewLineTruncator(0, KeepHead, extractor.NewlineLF, "SJIS")
 
 		// ACT
-		err := truncator.Truncate(input, output)
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 		// ASSERT
 		require.NoError(t, err)
@@ -223,50 +223,62 @@ func TestNewLineTruncator_SJIS(t *testing.T) {
 	}
 }
 
-func TestNewLineTruncator_InputFileNotFound(t *testing.T) {
-
-	// ARRANGE
-	d := t.TempDir()
-
-	input := filepath.Join(d, "xxxx")
-	output := filepath.Join(d, "output")
+func TestNewLineTruncator_InvalidEncoding(t *testing.T) {
 
 	// ACT
-	truncator, _ := NewLineTruncator(9, "UTF-8")
-	err := truncator.Truncate(input, output)
+	_, err := NewLineTruncator(9, KeepHead, extractor.NewlineLF, "utf")
 
 	// ASSERT
 	require.Error(t, err)
-	pathErr := err.(*os.PathError)
-	assert.Equal(t, input, pathErr.Path)
-	assert.Equal(t, "open", pathErr.Op)
+	assert.EqualError(t, err, "utf is invalid: htmlindex: invalid encoding name")
 }
 
-func TestNewLineTruncator_OutputFileNotFound(t *testing.T) {
+func TestNewLineTruncator_NewlineCR(t *testing.T) {
 
 	// ARRANGE
 	d := t.TempDir()
 
-	input := test.CreateFileWriteString(t, d, "input", "")
-	output := filepath.Join(d, "non", "output")
+	input := test.CreateFileWriteString(
+		t, d, "input", "1\r2\n3\r4\r")
+	output := filepath.Join(d, "output")
 
 	// ACT
-	truncator, _ := NewLineTruncator(9, "UTF-8")
-	err := truncator.Truncate(input, output)
+	truncator, err := NewLineTruncator(2, KeepHead, extractor.NewlineCR, "UTF-8")
+	require.NoError(t, err)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
 
 	// ASSERT
-	require.Error(t, err)
-	pathErr := err.(*os.PathError)
-	assert.Equal(t, output, pathErr.Path)
-	assert.Equal(t, "open", pathErr.Op)
+	require.NoError(t, err)
+	assert.Equal(t, "1\r2\n3\r", test.ReadString(t, output))
 }
 
-func TestNewLineTruncator_InvalidEncoding(t *testing.T) {
+func TestNewLineTruncator_KeepTail(t *testing.T) {
 
-	// ACT
-	_, err := NewLineTruncator(9, "utf")
+	// ARRANGE
+	d := t.TempDir()
 
-	// ASSERT
-	require.Error(t, err)
-	assert.EqualError(t, err, "utf is invalid: htmlindex: invalid encoding name")
+	input := test.CreateFileWriteString(t, d, "input", "1\n2\n3\n4\n5\n")
+
+	tests := []struct {
+		lineNum  int64
+		expected string
+	}{
+		{lineNum: 2, expected: "4\n5\n"},
+		{lineNum: 5, expected: "1\n2\n3\n4\n5\n"},
+		{lineNum: 6, expected: "1\n2\n3\n4\n5\n"},
+		{lineNum: 0, expected: ""},
+	}
+
+	for _, tt := range tests {
+		output := filepath.Join(d, "output")
+
+		// ACT
+		truncator, err := NewLineTruncator(tt.lineNum, KeepTail, extractor.NewlineLF, "UTF-8")
+		require.NoError(t, err)
+		err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, test.ReadString(t, output), "lineNum=%d", tt.lineNum)
+	}
 }
@@ -0,0 +1,43 @@
+package truncator
+
+import (
+	"github.com/onozaty/filep/extract/extractor"
+)
+
+// 行ごとに指定バイト数までに切り捨てます。(文字の途中では切り捨てません)
+// 改行は元のまま残し、切り捨てた行の末尾にはsuffixを付けます。(suffixInLimitの場合は、suffixを含めて指定バイト数に収めます)
+func NewPerLineByteTruncator(byteNum int64, suffix string, suffixInLimit bool, newline extractor.Newline, encodingName string) (*Truncator, error) {
+
+	extractor, err := extractor.NewPerLineByteExtractor(byteNum, suffix, suffixInLimit, newline, encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Truncator{
+		extractor: extractor,
+	}, nil
+}
+
+func NewPerLineCharTruncator(charNum int64, unit extractor.CharUnit, suffix string, suffixInLimit bool, newline extractor.Newline, encodingName string) (*Truncator, error) {
+
+	extractor, err := extractor.NewPerLineCharExtractor(charNum, unit, suffix, suffixInLimit, newline, encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Truncator{
+		extractor: extractor,
+	}, nil
+}
+
+func NewPerLineWidthTruncator(width int64, ambiguousWidth int, suffix string, suffixInLimit bool, newline extractor.Newline, encodingName string) (*Truncator, error) {
+
+	extractor, err := extractor.NewPerLineWidthExtractor(width, ambiguousWidth, suffix, suffixInLimit, newline, encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Truncator{
+		extractor: extractor,
+	}, nil
+}
@@ -0,0 +1,65 @@
+package truncator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/onozaty/filep/extract/extractor"
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPerLineByteTruncator(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "abcdef\nあいう\r\n123")
+	output := filepath.Join(d, "output")
+
+	// ACT
+	truncator, err := NewPerLineByteTruncator(4, "…", false, extractor.NewlineLF, "UTF-8")
+	require.NoError(t, err)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "abcd…\nあ…\r\n123", test.ReadString(t, output))
+}
+
+func TestNewPerLineCharTruncator(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "abcdef\nあいう\r\n123")
+	output := filepath.Join(d, "output")
+
+	// ACT
+	truncator, err := NewPerLineCharTruncator(2, extractor.CharUnitRune, "", false, extractor.NewlineLF, "UTF-8")
+	require.NoError(t, err)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "ab\nあい\r\n12", test.ReadString(t, output))
+}
+
+func TestNewPerLineWidthTruncator(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "abcdef\nあいう\r\n123")
+	output := filepath.Join(d, "output")
+
+	// ACT
+	truncator, err := NewPerLineWidthTruncator(3, 1, "~", false, extractor.NewlineLF, "UTF-8")
+	require.NoError(t, err)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "abc~\nあ~\r\n123", test.ReadString(t, output))
+}
@@ -0,0 +1,19 @@
+package truncator
+
+import (
+	"github.com/onozaty/filep/extract/extractor"
+)
+
+// 単位ごとに先頭から指定された大きさまでに切り捨て、切り捨てた場合のみ末尾にsuffixを付けます。
+// suffixInLimitの場合は、suffixを含めて指定された大きさに収めます。
+func NewSuffixTruncator(num int64, suffix string, suffixInLimit bool, units extractor.SizedUnits) (*Truncator, error) {
+
+	extractor, err := extractor.NewHeadSuffixExtractor(num, suffix, suffixInLimit, units)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Truncator{
+		extractor: extractor,
+	}, nil
+}
@@ -0,0 +1,126 @@
+package truncator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/onozaty/filep/extract/extractor"
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestNewByteSuffixTruncator(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "0123456789")
+
+	tests := []struct {
+		byteNum       int64
+		suffixInLimit bool
+		expected      string
+	}{
+		{byteNum: 5, suffixInLimit: false, expected: "01234[cut]"},
+		{byteNum: 5, suffixInLimit: true, expected: "[cut]"},
+		{byteNum: 8, suffixInLimit: true, expected: "012[cut]"},
+		{byteNum: 10, suffixInLimit: true, expected: "0123456789"},
+	}
+
+	for _, tt := range tests {
+		output := filepath.Join(d, "output")
+
+		// ACT
+		units, err := extractor.NewByteUnits("UTF-8")
+		require.NoError(t, err)
+		truncator, err := NewSuffixTruncator(tt.byteNum, "[cut]", tt.suffixInLimit, units)
+		require.NoError(t, err)
+		err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, test.ReadString(t, output), "byteNum=%d, suffixInLimit=%v", tt.byteNum, tt.suffixInLimit)
+	}
+}
+
+func TestNewByteCharBoundarySuffixTruncator(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "あいうえお")
+	output := filepath.Join(d, "output")
+
+	// ACT
+	units, err := extractor.NewByteCharBoundaryUnits("UTF-8")
+	require.NoError(t, err)
+	truncator, err := NewSuffixTruncator(10, "…", true, units)
+	require.NoError(t, err)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "あい…", test.ReadString(t, output))
+}
+
+func TestNewCharSuffixTruncator(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteBytes(t, d, "input", test.StringToByte(t, "あいうえお", japanese.ShiftJIS))
+	output := filepath.Join(d, "output")
+
+	// ACT
+	units, err := extractor.NewCharUnits(extractor.CharUnitRune, "sjis")
+	require.NoError(t, err)
+	truncator, err := NewSuffixTruncator(3, "…", false, units)
+	require.NoError(t, err)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "あいう…", test.ByteToString(t, test.ReadBytes(t, output), japanese.ShiftJIS))
+}
+
+func TestNewLineSuffixTruncator(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "1\n2\n3\n")
+	output := filepath.Join(d, "output")
+
+	// ACT
+	units, err := extractor.NewLineUnits(extractor.NewlineLF, "UTF-8")
+	require.NoError(t, err)
+	truncator, err := NewSuffixTruncator(2, "(more)\n", true, units)
+	require.NoError(t, err)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "1\n(more)\n", test.ReadString(t, output))
+}
+
+func TestNewWidthSuffixTruncator(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "あいうえお")
+	output := filepath.Join(d, "output")
+
+	// ACT
+	units, err := extractor.NewWidthUnits(2, "UTF-8")
+	require.NoError(t, err)
+	truncator, err := NewSuffixTruncator(5, "…", true, units)
+	require.NoError(t, err)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "あ…", test.ReadString(t, output))
+}
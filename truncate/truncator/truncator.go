@@ -1,34 +1,119 @@
 package truncator
 
 import (
-	"os"
+	"bytes"
+	"errors"
+	"io"
 
 	"github.com/onozaty/filep/extract/extractor"
 )
 
 type Truncator struct {
 	extractor extractor.Extractor
+	// 先頭を残す場合に、入力の先頭から残すバイト数を求めるもの(求められない場合はnil)
+	headSize func(input File) (int64, bool, error)
 }
 
-func (t *Truncator) Truncate(inputFilePath string, outputFilePath string) error {
-	return t.extractor.Extract(inputFilePath, outputFilePath)
+// ファイル自体を切り詰める場合の入力です。
+type File interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
 }
 
-// 空ファイルを作成するだけのExtractorです。
-type emptyExtractor struct {
+func (t *Truncator) Truncate(input io.Reader, output io.Writer) error {
+	return t.extractor.Extract(input, output)
+}
+
+// 切り捨てた結果が入力の先頭部分そのものとなる場合に、その長さ(バイト数)を返します。
+// ファイル自体をこの長さに切り詰めれば、内容を書き直さずに切り捨てられます。
+// 結果が入力の先頭部分とならない場合は、falseを返します。
+func (t *Truncator) HeadSize(input File) (int64, bool, error) {
+
+	if t.headSize == nil {
+		return 0, false, nil
+	}
+
+	return t.headSize(input)
+}
+
+// 残す部分を取り出すExtractorからTruncatorを作成します。
+// 先頭を残す場合は、残す部分までを読み込むことで、残すバイト数を求められるようにします。
+func newTruncator(extractor extractor.Extractor, keep Keep) *Truncator {
+
+	truncator := &Truncator{
+		extractor: extractor,
+	}
+
+	if keep == KeepHead {
+		truncator.headSize = func(input File) (int64, bool, error) {
+			return scanHeadSize(extractor, input)
+		}
+	}
+
+	return truncator
 }
 
-func (t *emptyExtractor) Extract(inputFilePath string, outputFilePath string) error {
+// 取り出した内容が入力の先頭部分と一致するかを確認しながら、その長さを求めます。
+// デコードとエンコードで元のバイト列に戻らない場合などは、一致しないためfalseを返します。
+func scanHeadSize(extractor extractor.Extractor, input File) (int64, bool, error) {
 
-	out, err := os.Create(outputFilePath)
+	output := &prefixWriter{input: input}
+	err := extractor.Extract(input, output)
+	if output.mismatched {
+		return 0, false, nil
+	}
 	if err != nil {
-		return err
+		return 0, false, err
 	}
-	return out.Close()
+
+	return output.size, true, nil
+}
+
+var errNotPrefix = errors.New("not a prefix of the input")
+
+// 書き込まれた内容を、入力の同じ位置の内容と比較しながら長さを数えるWriterです。
+type prefixWriter struct {
+	input      io.ReaderAt
+	size       int64
+	mismatched bool
+	buf        []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+
+	if len(w.buf) < len(p) {
+		w.buf = make([]byte, len(p))
+	}
+	buf := w.buf[:len(p)]
+
+	n, err := w.input.ReadAt(buf, w.size)
+	if n < len(p) && err != nil && err != io.EOF {
+		return 0, err
+	}
+	if n < len(p) || !bytes.Equal(buf, p) {
+		w.mismatched = true
+		return 0, errNotPrefix
+	}
+
+	w.size += int64(n)
+	return n, nil
+}
+
+// 何も出力しないExtractorです。
+type emptyExtractor struct {
+}
+
+func (t *emptyExtractor) Extract(input io.Reader, output io.Writer) error {
+	return nil
 }
 
 func newEmptyTruncator() (*Truncator, error) {
 	return &Truncator{
 		extractor: &emptyExtractor{},
+		headSize: func(input File) (int64, bool, error) {
+			// 何も残さない
+			return 0, true, nil
+		},
 	}, nil
 }
@@ -0,0 +1,139 @@
+package truncator
+
+import (
+	"testing"
+
+	"github.com/onozaty/filep/extract/extractor"
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestTruncator_HeadSize(t *testing.T) {
+
+	newChar := func(charNum int64, keep Keep, encodingName string) func() (*Truncator, error) {
+		return func() (*Truncator, error) {
+			return NewCharTruncator(charNum, keep, extractor.CharUnitRune, encodingName)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		newTruncator func() (*Truncator, error)
+		contents     []byte
+		expectedSize int64
+		expectedOk   bool
+	}{
+		{
+			name:         "byte",
+			newTruncator: func() (*Truncator, error) { return NewByteTruncator(5, KeepHead) },
+			contents:     []byte("abcdefghij"),
+			expectedSize: 5,
+			expectedOk:   true,
+		},
+		{
+			name:         "byte over",
+			newTruncator: func() (*Truncator, error) { return NewByteTruncator(20, KeepHead) },
+			contents:     []byte("abcdefghij"),
+			expectedSize: 10,
+			expectedOk:   true,
+		},
+		{
+			name:         "byte tail",
+			newTruncator: func() (*Truncator, error) { return NewByteTruncator(5, KeepTail) },
+			contents:     []byte("abcdefghij"),
+			expectedOk:   false,
+		},
+		{
+			name:         "zero",
+			newTruncator: func() (*Truncator, error) { return NewByteTruncator(0, KeepTail) },
+			contents:     []byte("abcdefghij"),
+			expectedSize: 0,
+			expectedOk:   true,
+		},
+		{
+			name: "char boundary",
+			newTruncator: func() (*Truncator, error) {
+				return NewByteCharBoundaryTruncator(5, KeepHead, "UTF-8")
+			},
+			contents:     []byte("あいう"),
+			expectedSize: 3,
+			expectedOk:   true,
+		},
+		{
+			name:         "char",
+			newTruncator: newChar(2, KeepHead, "UTF-8"),
+			contents:     []byte("あいう"),
+			expectedSize: 6,
+			expectedOk:   true,
+		},
+		{
+			name:         "char sjis",
+			newTruncator: newChar(2, KeepHead, "sjis"),
+			contents:     test.StringToByte(t, "あいう", japanese.ShiftJIS),
+			expectedSize: 4,
+			expectedOk:   true,
+		},
+		{
+			name:         "char tail",
+			newTruncator: newChar(2, KeepTail, "UTF-8"),
+			contents:     []byte("あいう"),
+			expectedOk:   false,
+		},
+		{
+			// 不正なバイトは置き換えられてしまうので、先頭部分とはならない
+			name:         "char invalid",
+			newTruncator: newChar(3, KeepHead, "UTF-8"),
+			contents:     []byte{'a', 0xFF, 'b', 'c'},
+			expectedOk:   false,
+		},
+		{
+			name: "line",
+			newTruncator: func() (*Truncator, error) {
+				return NewLineTruncator(2, KeepHead, extractor.NewlineCRLF, "UTF-8")
+			},
+			contents:     []byte("a\r\nb\r\nc\r\n"),
+			expectedSize: 6,
+			expectedOk:   true,
+		},
+		{
+			name: "width",
+			newTruncator: func() (*Truncator, error) {
+				return NewWidthTruncator(3, KeepHead, 1, "UTF-8")
+			},
+			contents:     []byte("あいう"),
+			expectedSize: 3,
+			expectedOk:   true,
+		},
+		{
+			name: "whole lines",
+			newTruncator: func() (*Truncator, error) {
+				return NewWholeLineByteTruncator(5, KeepHead, extractor.NewlineLF, "UTF-8")
+			},
+			contents:     []byte("ab\ncd\n"),
+			expectedSize: 3,
+			expectedOk:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			input := test.CreateFileWriteBytes(t, t.TempDir(), "input", tt.contents)
+
+			truncator, err := tt.newTruncator()
+			require.NoError(t, err)
+
+			// ACT
+			size, ok, err := truncator.HeadSize(test.OpenFile(t, input))
+
+			// ASSERT
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedOk, ok)
+			if tt.expectedOk {
+				assert.Equal(t, tt.expectedSize, size)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package truncator
+
+import (
+	"github.com/onozaty/filep/extract/extractor"
+)
+
+// 指定バイト数に収まる行までに切り捨てます。(行の途中では切り捨てません)
+func NewWholeLineByteTruncator(byteNum int64, keep Keep, newline extractor.Newline, encodingName string) (*Truncator, error) {
+
+	if byteNum == 0 {
+		// 0を指定された場合、空ファイルを作るだけ
+		return newEmptyTruncator()
+	}
+
+	// 残す部分を取り出すことで切り捨てと同じ扱いに
+	start, end := keep.position(byteNum)
+	extractor, err := extractor.NewWholeLineByteExtractor(start, end, newline, encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTruncator(extractor, keep), nil
+}
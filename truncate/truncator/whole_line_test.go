@@ -0,0 +1,84 @@
+package truncator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/onozaty/filep/extract/extractor"
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWholeLineByteTruncator(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "12\n345\n6789\n")
+
+	tests := []struct {
+		byteNum  int64
+		keep     Keep
+		expected string
+	}{
+		{byteNum: 7, keep: KeepHead, expected: "12\n345\n"},
+		{byteNum: 6, keep: KeepHead, expected: "12\n"},
+		{byteNum: 9, keep: KeepTail, expected: "345\n6789\n"},
+		{byteNum: 8, keep: KeepTail, expected: "6789\n"},
+		{byteNum: 0, keep: KeepHead, expected: ""},
+	}
+
+	for _, tt := range tests {
+		output := filepath.Join(d, "output")
+
+		// ACT
+		truncator, err := NewWholeLineByteTruncator(tt.byteNum, tt.keep, extractor.NewlineLF, "UTF-8")
+		require.NoError(t, err)
+		err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, test.ReadString(t, output), "byteNum=%d, keep=%d", tt.byteNum, tt.keep)
+	}
+}
+
+func TestNewWholeLineByteHeadTailTruncator(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "12\n345\n6789\n")
+	output := filepath.Join(d, "output")
+
+	// ACT
+	units, err := extractor.NewWholeLineByteUnits(extractor.NewlineLF, "UTF-8")
+	require.NoError(t, err)
+	truncator, err := NewHeadTailTruncator(3, 3, "--", units)
+	require.NoError(t, err)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "12\n--\n", test.ReadString(t, output))
+}
+
+func TestNewWholeLineByteSuffixTruncator(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(t, d, "input", "12\n345\n6789\n")
+	output := filepath.Join(d, "output")
+
+	// ACT
+	units, err := extractor.NewWholeLineByteUnits(extractor.NewlineLF, "UTF-8")
+	require.NoError(t, err)
+	truncator, err := NewSuffixTruncator(8, "..", false, units)
+	require.NoError(t, err)
+	err = truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, "12\n345\n..", test.ReadString(t, output))
+}
@@ -0,0 +1,20 @@
+package truncator
+
+import "github.com/onozaty/filep/extract/extractor"
+
+func NewWidthTruncator(width int64, keep Keep, ambiguousWidth int, encodingName string) (*Truncator, error) {
+
+	if width == 0 {
+		// 0を指定された場合、空ファイルを作るだけ
+		return newEmptyTruncator()
+	}
+
+	// 残す部分を取り出すことで切り捨てと同じ扱いに
+	start, end := keep.position(width)
+	extractor, err := extractor.NewWidthExtractor(start, end, ambiguousWidth, encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTruncator(extractor, keep), nil
+}
@@ -0,0 +1,133 @@
+package truncator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/onozaty/filep/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestNewWidthTruncator(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(
+		t, d, "input", "あいう123")
+
+	{
+		output := filepath.Join(d, "output9")
+
+		// ACT
+		truncator, _ := NewWidthTruncator(9, KeepHead, 1, "UTF-8")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			"あいう123",
+			test.ReadString(t, output))
+	}
+	{
+		output := filepath.Join(d, "output5")
+
+		// ACT
+		truncator, _ := NewWidthTruncator(5, KeepHead, 1, "UTF-8")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+		// ASSERT
+		require.NoError(t, err)
+		// 全角文字の途中で切れる場合は、その文字を含めない
+		assert.Equal(
+			t,
+			"あい",
+			test.ReadString(t, output))
+	}
+	{
+		output := filepath.Join(d, "output0")
+		truncator, _ := NewWidthTruncator(0, KeepHead, 1, "UTF-8")
+
+		// ACT
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			"",
+			test.ReadString(t, output))
+	}
+}
+
+func TestNewWidthTruncator_AmbiguousWidth(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteString(
+		t, d, "input", "①②③")
+
+	{
+		output := filepath.Join(d, "output1")
+
+		// ACT
+		truncator, _ := NewWidthTruncator(2, KeepHead, 1, "UTF-8")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			"①②",
+			test.ReadString(t, output))
+	}
+	{
+		output := filepath.Join(d, "output2")
+
+		// ACT
+		truncator, _ := NewWidthTruncator(2, KeepHead, 2, "UTF-8")
+		err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			"①",
+			test.ReadString(t, output))
+	}
+}
+
+func TestNewWidthTruncator_SJIS(t *testing.T) {
+
+	// ARRANGE
+	d := t.TempDir()
+
+	input := test.CreateFileWriteBytes(
+		t, d, "input", test.StringToByte(t, "ｱｲあいう", japanese.ShiftJIS))
+
+	output := filepath.Join(d, "output")
+
+	// ACT
+	truncator, _ := NewWidthTruncator(5, KeepHead, 1, "sjis")
+	err := truncator.Truncate(test.OpenFile(t, input), test.CreateFile(t, output))
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"ｱｲあ",
+		test.ByteToString(t, test.ReadBytes(t, output), japanese.ShiftJIS))
+}
+
+func TestNewWidthTruncator_InvalidAmbiguousWidth(t *testing.T) {
+
+	// ACT
+	_, err := NewWidthTruncator(1, KeepHead, 0, "UTF-8")
+
+	// ASSERT
+	assert.EqualError(t, err, "ambiguous width must be 1 or 2")
+}